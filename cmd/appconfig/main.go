@@ -0,0 +1,169 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command appconfig works with App Configuration files offline.
+//
+// Usage:
+//
+//	appconfig simulate -config current.json [-compare proposed.json] [-config-format yaml] -entities entities.csv -environment dev [-collection c1] [-json]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	AppConfiguration "github.com/IBM/appconfiguration-go-sdk/lib"
+)
+
+const usage = `usage: appconfig <command> [flags]
+
+commands:
+  simulate    report the value distribution of the feature flags for a set of entities,
+              and the entities whose value changes between two configurations
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "simulate":
+		err = simulate(os.Args[2:], os.Stdout)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "appconfig: unknown command %q\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "appconfig:", err)
+		os.Exit(1)
+	}
+}
+
+func simulate(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	configFile := flags.String("config", "", "baseline configuration, in the bootstrap file format, JSON or YAML (required)")
+	compareFile := flags.String("compare", "", "configuration compared against the baseline, e.g. with a raised rollout percentage")
+	configFormat := flags.String("config-format", "", "format of the configuration files, e.g. json or yaml. Defaults to the file extension")
+	entitiesFile := flags.String("entities", "", "entities to evaluate, a .csv or .jsonl file (required)")
+	format := flags.String("format", "", "format of the entities file, csv or jsonl. Defaults to the file extension")
+	environmentID := flags.String("environment", "", "environment id (required)")
+	collectionID := flags.String("collection", "", "collection id")
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*configFile) == 0 || len(*entitiesFile) == 0 || len(*environmentID) == 0 {
+		flags.Usage()
+		return fmt.Errorf("-config, -entities and -environment are required")
+	}
+
+	baseline, err := readConfiguration(*configFile, *configFormat)
+	if err != nil {
+		return err
+	}
+	var compare []byte
+	if len(*compareFile) > 0 {
+		if compare, err = readConfiguration(*compareFile, *configFormat); err != nil {
+			return err
+		}
+	}
+	if len(*format) == 0 {
+		*format = strings.TrimPrefix(filepath.Ext(*entitiesFile), ".")
+	}
+	file, err := os.Open(*entitiesFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	entities, err := AppConfiguration.ReadSimulationEntities(file, *format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *entitiesFile, err)
+	}
+
+	report, err := AppConfiguration.Simulate(baseline, compare, *environmentID, *collectionID, entities)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	printReport(out, report, compare != nil)
+	return nil
+}
+
+// readConfiguration reads a configuration file in the bootstrap file format, of the format if not empty, or of the
+// file extension, like the SDK reads the bootstrap files.
+func readConfiguration(path, format string) ([]byte, error) {
+	format, ok := AppConfiguration.BootstrapFormat(path, format)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported configuration format %q, set -config-format", path, format)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = AppConfiguration.DecodeBootstrap(data, format); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+func printReport(out io.Writer, report AppConfiguration.SimulationReport, compared bool) {
+	fmt.Fprintf(out, "%d entities\n", report.Entities)
+	for _, feature := range report.Features {
+		fmt.Fprintf(out, "\nfeature %s\n", feature.FeatureID)
+		printDistribution(out, "  distribution:", feature.Distribution, report.Entities)
+		if !compared {
+			continue
+		}
+		printDistribution(out, "  compared:    ", feature.CompareDistribution, report.Entities)
+		fmt.Fprintf(out, "  changed:      %d entities\n", len(feature.Changes))
+		for _, change := range feature.Changes {
+			before, _ := json.Marshal(change.Before)
+			after, _ := json.Marshal(change.After)
+			fmt.Fprintf(out, "    %s: %s -> %s\n", change.EntityID, before, after)
+		}
+	}
+}
+
+func printDistribution(out io.Writer, label string, distribution map[string]int, total int) {
+	values := make([]string, 0, len(distribution))
+	for value := range distribution {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	fmt.Fprint(out, label)
+	if total == 0 {
+		total = 1
+	}
+	for _, value := range values {
+		fmt.Fprintf(out, " %s=%d (%.1f%%)", value, distribution[value], 100*float64(distribution[value])/float64(total))
+	}
+	fmt.Fprintln(out)
+}
@@ -17,12 +17,16 @@
 package lib
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 	sm "github.com/IBM/secrets-manager-go-sdk/v2/secretsmanagerv2"
-	"path/filepath"
+	"io"
+	"io/fs"
+	"time"
 )
 
 // AppConfiguration : Struct having init and configInstance.
@@ -33,12 +37,383 @@ type AppConfiguration struct {
 }
 
 // ContextOptions : Struct having PersistentCacheDirectory path, BootstrapFile (ConfigurationFile) path and LiveConfigUpdateEnabled flag.
+//
+// BootstrapFormat is the format of the BootstrapFile: "json", "yaml" or a format added with RegisterBootstrapDecoder.
+// By default, the format is that of the file extension: .json, .yaml or .yml.
+//
+// BootstrapData and BootstrapReader provide the bootstrap configurations instead of the BootstrapFile, in the
+// BootstrapFormat, JSON by default. The BootstrapReader is read once, by SetContext. With BootstrapFS, the BootstrapFile
+// is a path of the file system, e.g. an embed.FS compiling the bootstrap file into the binary.
+//
+// BootstrapLayers are bootstrap configurations merged in order, e.g. a base file and the overlay of a region, instead
+// of the BootstrapFile, the BootstrapData or the BootstrapReader. See MergeBootstrapLayers.
+//
+// WatchBootstrapFile reloads the bootstrap configurations when a bootstrap file changes, e.g. a file of a Kubernetes
+// ConfigMap volume, and calls the configuration update listener. The files are polled every BootstrapWatchInterval,
+// 5 seconds by default. Only the files outside of a BootstrapFS are watched, and only when LiveConfigUpdateEnabled is
+// false. The listener is called on its own goroutine after the reload, so that it can call SetContext, which stops the
+// watch; it may then run after the context changed.
+//
+// RejectInvalidConfigurations rejects configurations that fail validation (see Validate) and keeps the previously
+// loaded configurations in use. By default, invalid configurations are loaded and the validation errors are logged.
+// Only the configurations of the environment and collection of the client are validated.
+//
+// NormalizeUnicode compares the entity attribute values and the segment rule values in Unicode NFC form, so that
+// composed and decomposed forms of the same text (e.g. "é" and "e\u0301") match.
+//
+// AssignmentStore makes the feature flag values sticky: once an entity is served the enabled value, a variant or a
+// segment override value, it keeps that value when the rollout percentages, the variant weights or the segment rules
+// change, until the assignment is reset in the store. See NewInMemoryAssignmentStore and NewFileAssignmentStore.
+//
+// ExposureSink receives an ExposureEvent for every feature flag evaluation, independently of the usage metering.
+// Repeated exposures of an entity to the same value of a feature flag are emitted once per ExposureDedupWindow;
+// a window of 0 emits every evaluation. See NewJSONLExposureSink and NewChannelExposureSink.
+//
+// HashSeed is the murmur3 seed of the percentage rollouts and of the variants. Leave it at 0 to bucket the entities
+// like the other App Configuration SDKs do; any other seed reshuffles every rollout.
+//
+// Clock is the clock against which the feature flag schedules are evaluated and the age of the configurations is
+// measured, see EvaluationDetails.DataAge, GetStatus and PersistentCacheMaxAge. Defaults to time.Now.
+//
+// CacheStore is the backend of the persistent cache. It takes precedence over PersistentCacheDirectory, which is a
+// shorthand for NewFileCacheStore(PersistentCacheDirectory). See also NewBoltCacheStore and NewMemoryCacheStore.
+//
+// CacheEncryptionKey encrypts the persistent cache with AES-GCM; the key is 16, 24 or 32 bytes long. CacheKeyProvider
+// supplies the keys instead, e.g. a KeyRing rotating the key. A cache that cannot be decrypted is ignored, and the
+// error logged.
+//
+// PersistentCacheMaxAge is the age beyond which the configurations of the persistent cache are stale, and
+// StalenessPolicy what the SDK does with stale configurations. By default, the persistent cache is never stale.
+//
+// HistorySize is the number of applied configurations kept for History and Rollback, 0 keeping none. HistoryStore
+// persists the history and the version pinned by Rollback, e.g. NewFileCacheStore of a directory other than
+// PersistentCacheDirectory; it is encrypted like the persistent cache.
 type ContextOptions struct {
-	PersistentCacheDirectory string
-	BootstrapFile            string
-	LiveConfigUpdateEnabled  bool
+	PersistentCacheDirectory    string
+	BootstrapFile               string
+	LiveConfigUpdateEnabled     bool
+	RejectInvalidConfigurations bool
+	NormalizeUnicode            bool
+	AssignmentStore             AssignmentStore
+	ExposureSink                ExposureSink
+	ExposureDedupWindow         time.Duration
+	HashSeed                    uint32
+	Clock                       func() time.Time
+	CacheStore                  CacheStore
+	CacheEncryptionKey          []byte
+	CacheKeyProvider            KeyProvider
+	PersistentCacheMaxAge       time.Duration
+	StalenessPolicy             StalenessPolicy
+	HistorySize                 int
+	HistoryStore                CacheStore
+	BootstrapFormat             string
+	BootstrapData               []byte
+	BootstrapReader             io.Reader
+	BootstrapFS                 fs.FS
+	BootstrapLayers             []BootstrapLayer
+	WatchBootstrapFile          bool
+	BootstrapWatchInterval      time.Duration
+}
+
+// BootstrapLayer : bootstrap configurations merged with the other layers of ContextOptions.BootstrapLayers, read from
+// the File, of the FS if set, the Data or the Reader, in the Format (see ContextOptions.BootstrapFormat).
+//
+// The environments, collections and segments of a layer are merged by ID with those of the previous layers, and the
+// features and properties of an environment by ID. The fields of an entry override those of the previous layers;
+// ReplaceEntries replaces the entries as a whole instead.
+type BootstrapLayer struct {
+	File           string
+	FS             fs.FS
+	Data           []byte
+	Reader         io.Reader
+	Format         string
+	ReplaceEntries bool
+}
+
+// sources returns the number of sources of the bootstrap configurations set in the layer.
+func (bl BootstrapLayer) sources() int {
+	sources := 0
+	for _, provided := range []bool{len(bl.File) > 0, len(bl.Data) > 0, bl.Reader != nil} {
+		if provided {
+			sources++
+		}
+	}
+	return sources
+}
+
+// validBootstrapLayer checks the file and the format of a bootstrap layer, and logs the errors.
+func validBootstrapLayer(layer BootstrapLayer) bool {
+	if _, ok := models.BootstrapFormat(layer.File, layer.Format); len(layer.File) > 0 && !ok {
+		log.Error(messages.InvalidBootstrapFile, " - ", layer.File)
+		return false
+	}
+	if layer.FS != nil && !fs.ValidPath(layer.File) {
+		log.Error(messages.InvalidBootstrapFile, " - ", layer.File)
+		return false
+	}
+	if _, ok := models.BootstrapFormat("", layer.Format); len(layer.Format) > 0 && !ok {
+		log.Error(messages.InvalidBootstrapFormat, " - ", layer.Format)
+		return false
+	}
+	return true
+}
+
+// MergeReport : the changes of the bootstrap layers over the first layer.
+type MergeReport = models.MergeReport
+
+// MergeChange : an entry of the bootstrap configurations added or changed by a bootstrap layer.
+type MergeChange = models.MergeChange
+
+// Config : configurations in the format of the bootstrap file.
+type Config = models.Config
+
+// MergeBootstrapLayers : Merge bootstrap layers like ContextOptions.BootstrapLayers, e.g. to inspect the configurations
+// of a region. The readers of the layers are read.
+func MergeBootstrapLayers(layers ...BootstrapLayer) (Config, MergeReport, error) {
+	var config Config
+	data, report, _, err := mergeBootstrapLayers(layers)
+	if err != nil {
+		return config, report, err
+	}
+	err = json.Unmarshal(data, &config)
+	return config, report, err
+}
+
+// BootstrapDecoder : converts a bootstrap file of some format into the JSON format of the bootstrap file.
+type BootstrapDecoder = models.BootstrapDecoder
+
+// RegisterBootstrapDecoder : Add a bootstrap file format, e.g. "toml" with the extension ".toml", or replace the
+// decoder of a format. Register the decoders before SetContext.
+func RegisterBootstrapDecoder(format string, decoder BootstrapDecoder, extensions ...string) {
+	models.RegisterBootstrapDecoder(format, decoder, extensions...)
+}
+
+// BootstrapFormat : Get the format of a bootstrap file, the explicit format if not empty, or the format registered for
+// the file extension, as SetContext does. ok is false if no decoder is registered for the format.
+func BootstrapFormat(path, format string) (string, bool) {
+	return models.BootstrapFormat(path, format)
+}
+
+// DecodeBootstrap : Convert a bootstrap file of the format, e.g. "yaml", into the JSON format of the bootstrap file,
+// e.g. to Validate it. The YAML errors are reported with their line.
+func DecodeBootstrap(data []byte, format string) ([]byte, error) {
+	return models.DecodeBootstrap(data, format)
+}
+
+// Snapshot : the configurations served by the SDK with their metadata, the format of ExportSnapshot.
+type Snapshot = models.Snapshot
+
+// SnapshotMetadata : where the configurations of a snapshot come from.
+type SnapshotMetadata = models.SnapshotMetadata
+
+// StalenessPolicy : what the SDK does with a persistent cache older than ContextOptions.PersistentCacheMaxAge.
+type StalenessPolicy int
+
+const (
+	// ServeStale serves the stale configurations, and logs a warning. This is the default policy.
+	ServeStale StalenessPolicy = iota
+	// ServeBootstrap ignores the stale configurations, and serves the bootstrap file, if any, instead.
+	ServeBootstrap
+	// FailReadiness serves the stale configurations, but GetStatus reports the SDK as not ready until fresh
+	// configurations are fetched from the service, e.g. to fail the readiness probe of a Kubernetes pod.
+	FailReadiness
+)
+
+// Status : the configurations served by the SDK, returned by GetStatus.
+type Status struct {
+	// Ready is true when configurations are served, and they are not stale under the FailReadiness policy.
+	Ready bool
+	// Source is where the served configurations come from, one of the DataSource constants.
+	Source string
+	// FetchedAt is when the configurations were fetched from the service, or when the bootstrap file was written.
+	// It is zero if unknown. Age is the time elapsed since.
+	FetchedAt time.Time
+	Age       time.Duration
+	// Stale is true when the configurations come from a persistent cache older than ContextOptions.PersistentCacheMaxAge.
+	Stale bool
+	// PinnedVersion is the version of the history served since Rollback, 0 if the live updates are not paused.
+	PinnedVersion int
 }
 
+// ConfigurationVersion : configurations applied by the SDK, kept in the history, see ContextOptions.HistorySize.
+type ConfigurationVersion = models.ConfigurationVersion
+
+// Sources of the served configurations, see Status and EvaluationDetails.
+const (
+	DataSourceService         = models.DataSourceService
+	DataSourcePersistentCache = models.DataSourcePersistentCache
+	DataSourceBootstrap       = models.DataSourceBootstrap
+	DataSourceSnapshot        = models.DataSourceSnapshot
+)
+
+// AttributeProvider : implemented by entities that supply their own attributes to Feature.GetCurrentValueFor and Property.GetCurrentValueFor.
+type AttributeProvider = models.AttributeProvider
+
+// AttributeResolver : lazily computes an entity attribute. It is invoked only when a segment rule references the attribute.
+type AttributeResolver = models.AttributeResolver
+
+// EvaluationOptions : optional settings passed to Feature.GetCurrentValueWithOptions and Property.GetCurrentValueWithOptions.
+type EvaluationOptions = models.EvaluationOptions
+
+// EvaluationDetails : the value of a feature flag evaluation, along with the matched segment, the served variant and the reason.
+type EvaluationDetails = models.EvaluationDetails
+
+// Variant : a weighted value of a multivariate feature flag.
+type Variant = models.Variant
+
+// SegmentMembership : whether an entity belongs to a segment, with the outcome of each of its rules.
+type SegmentMembership = models.SegmentMembership
+
+// RuleResult : the outcome of a segment rule for an entity, and why it passed or failed.
+type RuleResult = models.RuleResult
+
+// EvaluationTrace : the steps of a feature flag evaluation, returned by Explain.
+type EvaluationTrace = models.EvaluationTrace
+
+// SegmentRuleTrace : a segment rule checked by an evaluation, with the segments checked.
+type SegmentRuleTrace = models.SegmentRuleTrace
+
+// RolloutTrace : the rollout bucket of an entity compared against a rollout percentage.
+type RolloutTrace = models.RolloutTrace
+
+// Schedule : the activation window and the rollout ramp of a feature flag.
+type Schedule = models.Schedule
+
+// Ramp : a rollout percentage growing linearly over time.
+type Ramp = models.Ramp
+
+// Prerequisite : a feature flag, and the value it must evaluate to, that another feature flag depends on.
+type Prerequisite = models.Prerequisite
+
+// AssignmentStore : remembers the feature flag values served to the entities, see ContextOptions.AssignmentStore.
+type AssignmentStore = models.AssignmentStore
+
+// Assignment : the feature flag value served to an entity, as remembered by an AssignmentStore.
+type Assignment = models.Assignment
+
+// NewInMemoryAssignmentStore : Create an AssignmentStore that keeps the assignments in memory for the lifetime of the process.
+func NewInMemoryAssignmentStore() AssignmentStore {
+	return models.NewInMemoryAssignmentStore()
+}
+
+// FileAssignmentStore : an AssignmentStore persisting the assignments to a JSON file in the background. Call Flush to
+// write the latest assignments before the application exits.
+type FileAssignmentStore = models.FileAssignmentStore
+
+// NewFileAssignmentStore : Create an AssignmentStore that persists the assignments to the JSON file at path,
+// so that they survive application restarts.
+func NewFileAssignmentStore(path string) (*FileAssignmentStore, error) {
+	return models.NewFileAssignmentStore(path)
+}
+
+// CacheStore : the backend of the persistent cache, with Load, Save and Lock operations. See ContextOptions.CacheStore.
+type CacheStore = utils.CacheStore
+
+// FileCacheStore : a CacheStore keeping the configurations in the appconfiguration.json file of a directory.
+type FileCacheStore = utils.FileCacheStore
+
+// BoltCacheStore : a CacheStore keeping the configurations in a BoltDB file.
+type BoltCacheStore = utils.BoltCacheStore
+
+// MemoryCacheStore : a CacheStore keeping the configurations in memory, e.g. for tests.
+type MemoryCacheStore = utils.MemoryCacheStore
+
+// NewFileCacheStore : Create a store keeping the configurations in the appconfiguration.json file of the directory.
+// This is the store used for ContextOptions.PersistentCacheDirectory.
+func NewFileCacheStore(directory string) *FileCacheStore {
+	return utils.NewFileCacheStore(directory)
+}
+
+// NewBoltCacheStore : Open, or create, a BoltDB file keeping the configurations, e.g. on a writable volume of a
+// container with a read-only root file system. Close it on shutdown.
+func NewBoltCacheStore(path string) (*BoltCacheStore, error) {
+	return utils.NewBoltCacheStore(path)
+}
+
+// NewMemoryCacheStore : Create a store keeping the configurations in memory, for the lifetime of the process.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return utils.NewMemoryCacheStore()
+}
+
+// KeyProvider : the source of the AES keys encrypting the persistent cache, see ContextOptions.CacheKeyProvider.
+type KeyProvider = utils.KeyProvider
+
+// KeyRing : a KeyProvider with a current key and previous keys, still decrypting the caches encrypted with them.
+type KeyRing = utils.KeyRing
+
+// NewKeyRing : Create a key ring encrypting with the key of currentKeyID. To rotate the key, add a new key to the
+// keys and make it current: the cache is encrypted with it on the next save.
+func NewKeyRing(currentKeyID string, keys map[string][]byte) (*KeyRing, error) {
+	return utils.NewKeyRing(currentKeyID, keys)
+}
+
+// ExposureEvent : an entity was served a value of a feature flag, see ContextOptions.ExposureSink.
+type ExposureEvent = models.ExposureEvent
+
+// ExposureSink : receives the exposure events of the feature flag evaluations.
+type ExposureSink = models.ExposureSink
+
+// JSONLExposureSink : an ExposureSink appending the events to a file, one JSON object per line, in the background.
+type JSONLExposureSink = models.JSONLExposureSink
+
+// ChannelExposureSink : an ExposureSink sending the events to a channel, dropping and counting them when the channel
+// is full.
+type ChannelExposureSink = models.ChannelExposureSink
+
+// NewJSONLExposureSink : Create a sink appending the exposure events to the file at path. Close it on shutdown.
+func NewJSONLExposureSink(path string) (*JSONLExposureSink, error) {
+	return models.NewJSONLExposureSink(path)
+}
+
+// NewChannelExposureSink : Create a sink sending the exposure events to the channel, without blocking the evaluations.
+func NewChannelExposureSink(events chan<- ExposureEvent) *ChannelExposureSink {
+	return models.NewChannelExposureSink(events)
+}
+
+// SimulationEntity : an entity, and its attributes, evaluated by Simulate.
+type SimulationEntity = models.SimulationEntity
+
+// SimulationChange : an entity whose value of a feature flag differs between the two simulated configurations.
+type SimulationChange = models.SimulationChange
+
+// FeatureSimulation : the simulated value distribution, and value changes, of a feature flag.
+type FeatureSimulation = models.FeatureSimulation
+
+// SimulationReport : the result of Simulate.
+type SimulationReport = models.SimulationReport
+
+// Simulate : Evaluate the feature flags of the baseline configuration (in the bootstrap file format) for each of the
+// entities, and report the value distribution of each feature flag. When compare is not nil, the entities whose value
+// changes in the compare configuration are reported too, e.g. to preview the effect of raising a rollout percentage.
+// The simulation does not need an initialized AppConfiguration instance and has no side effects.
+func Simulate(baseline, compare []byte, environmentID, collectionID string, entities []SimulationEntity) (SimulationReport, error) {
+	return models.Simulate(baseline, compare, environmentID, collectionID, entities)
+}
+
+// ReadSimulationEntities : Read the entities of a simulation, in the "csv" or "jsonl" format.
+func ReadSimulationEntities(r io.Reader, format string) ([]SimulationEntity, error) {
+	return models.ReadSimulationEntities(r, format)
+}
+
+// The reasons reported in the EvaluationDetails.
+const (
+	ReasonFeatureDisabled        = models.ReasonFeatureDisabled
+	ReasonSegmentMatch           = models.ReasonSegmentMatch
+	ReasonSegmentRolloutExcluded = models.ReasonSegmentRolloutExcluded
+	ReasonRolloutIncluded        = models.ReasonRolloutIncluded
+	ReasonRolloutExcluded        = models.ReasonRolloutExcluded
+	ReasonStickyAssignment       = models.ReasonStickyAssignment
+	ReasonPrerequisiteFailed     = models.ReasonPrerequisiteFailed
+	ReasonScheduleInactive       = models.ReasonScheduleInactive
+	ReasonError                  = models.ReasonError
+)
+
+// ValidationReport : errors and warnings found by the configuration validator.
+type ValidationReport = models.ValidationReport
+
+// ValidationIssue : a single error or warning of a ValidationReport.
+type ValidationIssue = models.ValidationIssue
+
 var appConfigurationInstance *AppConfiguration
 
 var overrideServiceUrl = ""
@@ -145,11 +520,32 @@ func (ac *AppConfiguration) SetContext(collectionID string, environmentID string
 		})
 	case 1:
 		var temp = options[0]
-		if len(temp.BootstrapFile) > 0 && filepath.Ext(temp.BootstrapFile) != ".json" {
-			log.Error(messages.InvalidBootstrapFile, " - ", temp.BootstrapFile)
+		layers := temp.BootstrapLayers
+		bootstrap := BootstrapLayer{File: temp.BootstrapFile, FS: temp.BootstrapFS, Data: temp.BootstrapData, Reader: temp.BootstrapReader, Format: temp.BootstrapFormat}
+		if sources := bootstrap.sources(); sources > 1 || sources == 1 && len(layers) > 0 {
+			log.Error(messages.MultipleBootstrapSources)
 			return
+		} else if sources == 1 {
+			if !validBootstrapLayer(bootstrap) {
+				return
+			}
 		}
-		if !temp.LiveConfigUpdateEnabled && len(temp.BootstrapFile) == 0 {
+		for _, layer := range layers {
+			if layer.sources() != 1 {
+				log.Error(messages.InvalidBootstrapLayer)
+				return
+			}
+			if !validBootstrapLayer(layer) {
+				return
+			}
+		}
+		if len(temp.CacheEncryptionKey) > 0 {
+			if err := utils.ValidateEncryptionKey(temp.CacheEncryptionKey); err != nil {
+				log.Error(messages.InvalidCacheEncryptionKey, err)
+				return
+			}
+		}
+		if !temp.LiveConfigUpdateEnabled && bootstrap.sources() == 0 && len(layers) == 0 {
 			log.Error(messages.BootstrapFileNotFoundError)
 			return
 		}
@@ -236,6 +632,124 @@ func (ac *AppConfiguration) GetSecret(propertyID string, secretsManagerService *
 	return models.SecretProperty{}, errors.New(messages.InitError)
 }
 
+// Validate checks configurations in the format of the bootstrap file (as exported by `ibmcloud ac export`) for
+// malformed data, such as non-string rule values, unknown operators, non-numeric rollout percentages and
+// references to segments that do not exist.
+func Validate(config []byte) ValidationReport {
+	return models.Validate(config)
+}
+
+// GetSegmentsForEntity returns the membership of the entity in every segment of the configurations, sorted by segment ID,
+// with the outcome of each segment rule. The entity belongs to the segments whose Matched field is true.
+func (ac *AppConfiguration) GetSegmentsForEntity(entityID string, entityAttributes map[string]interface{}) ([]SegmentMembership, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.getSegmentsForEntity(entityID, entityAttributes)
+	}
+	log.Error(messages.CollectionInitError)
+	return nil, errors.New(messages.InitError)
+}
+
+// Explain evaluates the feature flag for the entity, without side effects, and returns the steps of the evaluation:
+// the segment rules checked in order, the rules of their segments, the rollout buckets and the final decision.
+// The trace renders as text with String() and as JSON with JSON().
+func (ac *AppConfiguration) Explain(featureID string, entityID string, entityAttributes map[string]interface{}) (EvaluationTrace, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		feature, err := ac.configurationHandlerInstance.getFeature(featureID)
+		if err != nil {
+			return EvaluationTrace{}, err
+		}
+		return feature.Explain(entityID, entityAttributes), nil
+	}
+	log.Error(messages.CollectionInitError)
+	return EvaluationTrace{}, errors.New(messages.ErrorInvalidFeatureAction)
+}
+
+// GetValidationReport returns the validation report of the configurations in use. The report of rejected configurations,
+// see RejectInvalidConfigurations, is only logged.
+func (ac *AppConfiguration) GetValidationReport() (ValidationReport, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.getValidationReport(), nil
+	}
+	log.Error(messages.CollectionInitError)
+	return ValidationReport{}, errors.New(messages.InitError)
+}
+
+// GetBootstrapMergeReport returns the changes of the bootstrap layers over the first layer, when the configurations were
+// last read from the BootstrapLayers.
+func (ac *AppConfiguration) GetBootstrapMergeReport() (MergeReport, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.getMergeReport(), nil
+	}
+	log.Error(messages.CollectionInitError)
+	return MergeReport{}, errors.New(messages.InitError)
+}
+
+// GetStatus returns the source and the age of the configurations served, and whether the SDK is ready.
+func (ac *AppConfiguration) GetStatus() (Status, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.getStatus(), nil
+	}
+	log.Error(messages.CollectionInitError)
+	return Status{}, errors.New(messages.InitError)
+}
+
+// ExportSnapshot returns the configurations served by the SDK, with their metadata, e.g. for an incident review.
+// The snapshot is in the format of a bootstrap file, and can be loaded back with LoadSnapshot.
+func (ac *AppConfiguration) ExportSnapshot() ([]byte, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.exportSnapshot()
+	}
+	log.Error(messages.CollectionInitError)
+	return nil, errors.New(messages.InitError)
+}
+
+// LoadSnapshot replaces the served configurations with those of a snapshot, and calls the configuration update
+// listener. It needs no network connection. The configurations are those of the environment and the collection of
+// the snapshot, which can differ from the context of the SDK; a bootstrap file without metadata is read for the
+// context of the SDK. The next configurations fetched from the service replace the snapshot.
+func (ac *AppConfiguration) LoadSnapshot(data []byte) error {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.loadSnapshot(data)
+	}
+	log.Error(messages.CollectionInitError)
+	return errors.New(messages.InitError)
+}
+
+// History returns the last applied configurations, oldest first, see ContextOptions.HistorySize.
+func (ac *AppConfiguration) History() ([]ConfigurationVersion, error) {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.getHistory()
+	}
+	log.Error(messages.CollectionInitError)
+	return nil, errors.New(messages.InitError)
+}
+
+// Rollback serves the configurations of a version of the History, and calls the configuration update listener.
+// The live updates are paused, and the configurations remain pinned to the version, until Resume is called. With a
+// HistoryStore, the pinned version is persisted along with the history and restored by SetContext after a restart.
+func (ac *AppConfiguration) Rollback(version int) error {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.rollback(version)
+	}
+	log.Error(messages.CollectionInitError)
+	return errors.New(messages.InitError)
+}
+
+// Resume ends a Rollback: the latest configurations of the History are served again, and the live updates resume.
+func (ac *AppConfiguration) Resume() error {
+	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
+		return ac.configurationHandlerInstance.resume()
+	}
+	log.Error(messages.CollectionInitError)
+	return errors.New(messages.InitError)
+}
+
+// IsReady tells whether the SDK serves configurations that are not stale under the FailReadiness policy.
+func (ac *AppConfiguration) IsReady() bool {
+	status, err := ac.GetStatus()
+	return err == nil && status.Ready
+}
+
 // EnableDebug : Enable Debug
 func (ac *AppConfiguration) EnableDebug(enabled bool) {
 	if enabled {
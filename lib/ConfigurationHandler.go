@@ -21,8 +21,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
-	"path/filepath"
+	"os"
 	"sync"
 	"time"
 
@@ -55,13 +57,24 @@ type ConfigurationHandler struct {
 	cache                       *models.Cache
 	configurationUpdateListener configurationUpdateListenerFunc
 	persistentCacheDirectory    string
-	bootstrapFile               string
+	cacheStore                  utils.CacheStore
+	persistentCacheMaxAge       time.Duration
+	stalenessPolicy             StalenessPolicy
+	history                     *models.History
+	historyStore                utils.CacheStore
+	pinnedVersion               int
+	bootstrapLayers             []BootstrapLayer
+	mergeReport                 models.MergeReport
+	bootstrapWatcher            *utils.FileWatcher
 	liveConfigUpdateEnabled     bool
+	rejectInvalidConfigurations bool
+	validationReport            models.ValidationReport
 	persistentData              []byte
 	retryInterval               int64
 	scheduledRetry              *time.Timer
 	socketConnection            *websocket.Conn
 	socketConnectionResponse    *http.Response
+	persisting                  sync.WaitGroup
 	mu                          sync.Mutex
 }
 
@@ -85,64 +98,334 @@ func (ch *ConfigurationHandler) Init(region, guid, apikey string, usePrivateEndp
 
 // SetContext : Set Context
 func (ch *ConfigurationHandler) SetContext(collectionID, environmentID string, options ContextOptions) {
+	// the bootstrap reload and the persistent writes of the previous context must not see the context change
+	if ch.bootstrapWatcher != nil {
+		ch.bootstrapWatcher.Stop()
+		ch.bootstrapWatcher = nil
+	}
+	ch.waitPersisted()
 	ch.collectionID = collectionID
 	ch.environmentID = environmentID
 	ch.urlBuilder = utils.GetInstance()
 	ch.urlBuilder.Init(ch.collectionID, ch.environmentID, ch.region, ch.guid, ch.apikey, overrideServiceUrl, ch.usePrivateEndpoint)
 	utils.GetMeteringInstance().Init(ch.guid, environmentID, collectionID)
 	ch.persistentCacheDirectory = options.PersistentCacheDirectory
-	ch.bootstrapFile = options.BootstrapFile
+	ch.cacheStore = options.CacheStore
+	if ch.cacheStore == nil && len(ch.persistentCacheDirectory) > 0 {
+		ch.cacheStore = utils.NewFileCacheStore(ch.persistentCacheDirectory)
+	}
+	ch.historyStore = options.HistoryStore
+	keyProvider := options.CacheKeyProvider
+	if keyProvider == nil && len(options.CacheEncryptionKey) > 0 {
+		var err error
+		if keyProvider, err = utils.NewStaticKeyProvider(options.CacheEncryptionKey); err != nil {
+			log.Error(messages.InvalidCacheEncryptionKey, err)
+		}
+	}
+	if keyProvider != nil {
+		if ch.cacheStore != nil {
+			ch.cacheStore = utils.NewEncryptedCacheStore(ch.cacheStore, keyProvider)
+		}
+		if ch.historyStore != nil {
+			ch.historyStore = utils.NewEncryptedCacheStore(ch.historyStore, keyProvider)
+		}
+	}
+	ch.mu.Lock()
+	ch.pinnedVersion = 0
+	ch.history = nil
+	if options.HistorySize > 0 {
+		ch.history = models.NewHistory(options.HistorySize)
+	}
+	ch.mu.Unlock()
+	if ch.history != nil && ch.historyStore != nil {
+		ch.loadHistory()
+	}
+	ch.persistentCacheMaxAge = options.PersistentCacheMaxAge
+	ch.stalenessPolicy = options.StalenessPolicy
+	ch.setBootstrapLayers(options)
 	ch.liveConfigUpdateEnabled = options.LiveConfigUpdateEnabled
+	ch.rejectInvalidConfigurations = options.RejectInvalidConfigurations
+	ch.watchBootstrapFiles(options)
+	models.SetUnicodeNormalization(options.NormalizeUnicode)
+	models.SetAssignmentStore(options.AssignmentStore)
+	models.SetExposureSink(options.ExposureSink, options.ExposureDedupWindow)
+	models.SetHashSeed(options.HashSeed)
+	models.SetClock(options.Clock)
+	ch.restorePinnedVersion()
 	ch.isInitialized = true
 	ch.retryInterval = 2 // two minutes
 }
 func (ch *ConfigurationHandler) loadData() {
+	if ch.getPinnedVersion() != 0 {
+		log.Info(messages.LiveUpdatesPaused)
+		return
+	}
 	persistentCacheRead := false
 
-	if len(ch.persistentCacheDirectory) > 0 {
-		path := filepath.Join(utils.SanitizePath(ch.persistentCacheDirectory), constants.ConfigurationFile)
-		log.Info(messages.ReadPersistentCache, path)
-		ch.persistentData = utils.ReadFiles(path)
-		if !bytes.Equal(ch.persistentData, []byte(`{}`)) {
-			configurations, err := models.ExtractConfigurations(ch.persistentData, ch.environmentID, ch.collectionID)
-			if err != nil {
-				log.Error("Error occurred while reading persistent cache configurations - ", err.Error())
-			} else {
-				ch.saveInCache(configurations)
-				persistentCacheRead = true
-			}
-		}
-	}
-	if len(ch.bootstrapFile) > 0 {
-		path := utils.SanitizePath(ch.bootstrapFile)
-		if len(ch.persistentCacheDirectory) > 0 {
-			if !persistentCacheRead {
-				bootstrapFileData := utils.ReadFiles(path)
-				bootstrapConfigurations, err := models.ExtractConfigurations(bootstrapFileData, ch.environmentID, ch.collectionID)
+	if ch.cacheStore != nil {
+		log.Info(messages.ReadPersistentCache, describeCacheStore(ch.cacheStore))
+		var metadata utils.CacheMetadata
+		ch.persistentData, metadata = utils.LoadConfigurationsWithMetadata(ch.cacheStore, ch.environmentID, ch.collectionID)
+		if !bytes.Equal(ch.persistentData, []byte(`{}`)) && ch.servePersistentCache(metadata.FetchedAt) {
+			if report, valid := ch.validateConfigurations(ch.persistentData, "persistent cache", ch.environmentID, ch.collectionID); valid {
+				configurations, err := models.ExtractConfigurations(ch.persistentData, ch.environmentID, ch.collectionID)
 				if err != nil {
-					log.Error("Error occurred while reading bootstrap configurations - ", err.Error())
+					log.Error("Error occurred while reading persistent cache configurations - ", err.Error())
 				} else {
-					ch.saveInCache(bootstrapConfigurations)
-					go utils.StoreFiles(string(models.FormatConfig(bootstrapConfigurations, ch.environmentID, ch.collectionID)), ch.persistentCacheDirectory)
-
+					persistentCacheRead = ch.saveValidatedInCache(configurations, models.DataSourcePersistentCache, metadata.FetchedAt, report)
 				}
 			}
-		} else {
-			log.Info(messages.ReadBootstrapConfigurations, path)
-			bootstrapFileData := utils.ReadFiles(path)
-			bootstrapConfigurations, err := models.ExtractConfigurations(bootstrapFileData, ch.environmentID, ch.collectionID)
-			if err != nil {
-				log.Error("Error occurred while reading bootstrap configurations - ", err.Error())
-			} else {
-				ch.saveInCache(bootstrapConfigurations)
-			}
 		}
 	}
+	if len(ch.bootstrapLayers) > 0 && !persistentCacheRead {
+		ch.loadBootstrap()
+	}
 	if ch.liveConfigUpdateEnabled {
 		ch.FetchConfigurationData()
 	}
 }
 
+// loadBootstrap reads, validates and loads the bootstrap configurations, and writes them to the persistent cache.
+// It returns false if the configurations were not loaded.
+func (ch *ConfigurationHandler) loadBootstrap() bool {
+	bootstrapData, modTime := ch.readBootstrap()
+	report, valid := ch.validateConfigurations(bootstrapData, "bootstrap file", ch.environmentID, ch.collectionID)
+	if !valid {
+		return false
+	}
+	bootstrapConfigurations, err := models.ExtractConfigurations(bootstrapData, ch.environmentID, ch.collectionID)
+	if err != nil {
+		log.Error("Error occurred while reading bootstrap configurations - ", err.Error())
+		return false
+	}
+	if !ch.saveValidatedInCache(bootstrapConfigurations, models.DataSourceBootstrap, modTime, report) {
+		return false
+	}
+	if ch.cacheStore != nil {
+		store, content := ch.cacheStore, string(models.FormatConfig(bootstrapConfigurations, ch.environmentID, ch.collectionID))
+		metadata := utils.CacheMetadata{FetchedAt: modTime, EnvironmentID: ch.environmentID, CollectionID: ch.collectionID}
+		ch.persist(func() { utils.StoreConfigurationsWithMetadata(store, content, metadata) })
+	}
+	return true
+}
+
+// persist runs a write to the persistent stores in the background, see waitPersisted.
+func (ch *ConfigurationHandler) persist(write func()) {
+	ch.persisting.Add(1)
+	go func() {
+		defer ch.persisting.Done()
+		write()
+	}()
+}
+
+// waitPersisted waits for the writes to the persistent stores started by persist.
+func (ch *ConfigurationHandler) waitPersisted() {
+	ch.persisting.Wait()
+}
+
+// watchBootstrapFiles watches the bootstrap files of the file system with WatchBootstrapFile.
+func (ch *ConfigurationHandler) watchBootstrapFiles(options ContextOptions) {
+	if !options.WatchBootstrapFile {
+		return
+	}
+	if ch.liveConfigUpdateEnabled {
+		log.Warn(messages.WatchBootstrapFileLive)
+		return
+	}
+	var paths []string
+	for _, layer := range ch.bootstrapLayers {
+		if len(layer.File) > 0 && layer.FS == nil {
+			paths = append(paths, utils.SanitizePath(layer.File))
+		}
+	}
+	if len(paths) == 0 {
+		log.Warn(messages.NoBootstrapFileToWatch)
+		return
+	}
+	interval := options.BootstrapWatchInterval
+	if interval <= 0 {
+		interval = constants.DefaultBootstrapWatchInterval * time.Second
+	}
+	ch.bootstrapWatcher = utils.NewFileWatcher(paths, interval, ch.reloadBootstrap)
+	ch.bootstrapWatcher.Start()
+}
+
+// reloadBootstrap reloads the bootstrap configurations after a change of the bootstrap files, and calls the
+// configuration update listener. The configurations in use are kept if the new ones cannot be loaded.
+func (ch *ConfigurationHandler) reloadBootstrap() {
+	if ch.getPinnedVersion() != 0 {
+		log.Info(messages.LiveUpdatesPaused)
+		return
+	}
+	log.Info(messages.BootstrapFileChanged)
+	if ch.loadBootstrap() && ch.configurationUpdateListener != nil {
+		// the listener runs after the watcher callback returns, as it may call SetContext, which waits for the callback
+		go ch.configurationUpdateListener()
+	}
+}
+
+// setBootstrapLayers sets the bootstrap layers of the options, a single layer unless the BootstrapLayers are set.
+// The readers are read here, once.
+func (ch *ConfigurationHandler) setBootstrapLayers(options ContextOptions) {
+	layers := options.BootstrapLayers
+	if len(layers) == 0 && (len(options.BootstrapFile) > 0 || len(options.BootstrapData) > 0 || options.BootstrapReader != nil) {
+		layers = []BootstrapLayer{{
+			File:   options.BootstrapFile,
+			FS:     options.BootstrapFS,
+			Data:   options.BootstrapData,
+			Reader: options.BootstrapReader,
+			Format: options.BootstrapFormat,
+		}}
+	}
+	ch.bootstrapLayers = make([]BootstrapLayer, 0, len(layers))
+	for _, layer := range layers {
+		layer.Data = append([]byte(nil), layer.Data...)
+		if layer.Reader != nil {
+			data, err := io.ReadAll(layer.Reader)
+			if err != nil {
+				log.Error(messages.ReadBootstrapReaderErr, err)
+			}
+			layer.Data, layer.Reader = data, nil
+		}
+		ch.bootstrapLayers = append(ch.bootstrapLayers, layer)
+	}
+	ch.mu.Lock()
+	ch.mergeReport = models.MergeReport{}
+	ch.mu.Unlock()
+}
+
+// readBootstrap reads the bootstrap configurations, converted into the JSON format, and merges the layers. It returns
+// their latest modification time, zero if unknown.
+func (ch *ConfigurationHandler) readBootstrap() ([]byte, time.Time) {
+	var data []byte
+	var modTime time.Time
+	var err error
+	if len(ch.bootstrapLayers) == 1 {
+		data, modTime, err = readBootstrapLayer(ch.bootstrapLayers[0])
+	} else {
+		var report models.MergeReport
+		if data, report, modTime, err = mergeBootstrapLayers(ch.bootstrapLayers); err == nil {
+			log.Info(messages.BootstrapLayersMerged, len(report.Changes))
+			for _, change := range report.Changes {
+				log.Debug(change.String())
+			}
+			ch.mu.Lock()
+			ch.mergeReport = report
+			ch.mu.Unlock()
+		}
+	}
+	if err != nil {
+		log.Error("Error occurred while reading bootstrap configurations - ", err.Error())
+		return []byte(`{}`), modTime
+	}
+	return data, modTime
+}
+
+// readBootstrapLayer reads a bootstrap layer, converted into the JSON format, from its Data, its File of its FS or its
+// File. It returns its modification time, zero if unknown.
+func readBootstrapLayer(layer BootstrapLayer) ([]byte, time.Time, error) {
+	var data []byte
+	var modTime time.Time
+	var err error
+	path, format := layer.File, layer.Format
+	switch {
+	case len(layer.Data) > 0:
+		log.Info(messages.ReadBootstrapData)
+		path, data = "bootstrap data", layer.Data
+		if len(format) == 0 {
+			format = "json"
+		}
+	case layer.FS != nil:
+		log.Info(messages.ReadBootstrapConfigurations, path)
+		if data, err = fs.ReadFile(layer.FS, path); err != nil {
+			return nil, modTime, err
+		}
+		if info, err := fs.Stat(layer.FS, path); err == nil {
+			modTime = info.ModTime()
+		}
+	case len(path) > 0:
+		path = utils.SanitizePath(path)
+		log.Info(messages.ReadBootstrapConfigurations, path)
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, modTime, err
+		}
+		modTime = fileModTime(path)
+	default:
+		return nil, modTime, errors.New(messages.EmptyBootstrapLayer)
+	}
+	format, _ = models.BootstrapFormat(path, format)
+	if data, err = models.DecodeBootstrap(data, format); err != nil {
+		return nil, modTime, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, modTime, nil
+}
+
+// mergeBootstrapLayers reads and merges the bootstrap layers. It returns their latest modification time, zero if
+// unknown.
+func mergeBootstrapLayers(layers []BootstrapLayer) ([]byte, models.MergeReport, time.Time, error) {
+	mergeLayers := make([]models.MergeLayer, len(layers))
+	var modTime time.Time
+	for i, layer := range layers {
+		data, layerModTime, err := readBootstrapLayer(layer)
+		if err != nil {
+			return nil, models.MergeReport{}, modTime, fmt.Errorf("bootstrap layer %d: %w", i, err)
+		}
+		if layerModTime.After(modTime) {
+			modTime = layerModTime
+		}
+		mergeLayers[i] = models.MergeLayer{Data: data, ReplaceEntries: layer.ReplaceEntries}
+	}
+	data, report, err := models.MergeBootstrap(mergeLayers)
+	return data, report, modTime, err
+}
+
+func (ch *ConfigurationHandler) getMergeReport() models.MergeReport {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.mergeReport
+}
+
+// servePersistentCache applies the staleness policy to a persistent cache fetched at fetchedAt.
+func (ch *ConfigurationHandler) servePersistentCache(fetchedAt time.Time) bool {
+	if ch.persistentCacheMaxAge <= 0 {
+		return true
+	}
+	age := "an unknown time"
+	if !fetchedAt.IsZero() {
+		elapsed := models.Now().Sub(fetchedAt)
+		if elapsed <= ch.persistentCacheMaxAge {
+			return true
+		}
+		age = elapsed.Round(time.Second).String()
+	}
+	if ch.stalenessPolicy == ServeBootstrap {
+		log.Warn(messages.PersistentCacheStaleIgnored, age, " ago")
+		return false
+	}
+	log.Warn(messages.PersistentCacheStale, age, " ago")
+	return true
+}
+
+// fileModTime returns the modification time of the file, zero if unknown.
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// describeCacheStore names the persistent cache store in the logs.
+func describeCacheStore(store utils.CacheStore) string {
+	if encryptedStore, ok := store.(*utils.EncryptedCacheStore); ok {
+		return describeCacheStore(encryptedStore.Store()) + " (encrypted)"
+	}
+	if fileStore, ok := store.(*utils.FileCacheStore); ok {
+		return fileStore.Path()
+	}
+	return fmt.Sprintf("%T", store)
+}
+
 // FetchConfigurationData : Fetch Configuration Data
 func (ch *ConfigurationHandler) FetchConfigurationData() {
 	log.Debug(messages.FetchConfigurationData)
@@ -151,14 +434,114 @@ func (ch *ConfigurationHandler) FetchConfigurationData() {
 		go ch.startWebSocket()
 	}
 }
+
+// validateConfigurations runs the configuration validator on the configurations of the environment and collection in
+// the data read from source, and logs its findings. It returns false when the configurations are invalid and must be rejected, so that the previous configurations remain in use.
+// The report is kept by saveValidatedInCache, once the configurations are applied.
+func (ch *ConfigurationHandler) validateConfigurations(data []byte, source, environmentID, collectionID string) (models.ValidationReport, bool) {
+	report := models.ValidateFor(data, environmentID, collectionID)
+	for _, issue := range report.Warnings {
+		log.Warn(messages.ConfigurationValidationIssue, source, " - ", issue.String())
+	}
+	for _, issue := range report.Errors {
+		log.Error(messages.ConfigurationValidationIssue, source, " - ", issue.String())
+	}
+	if !report.IsValid() && ch.rejectInvalidConfigurations {
+		log.Error(messages.InvalidConfigurationsRejected, source)
+		return report, false
+	}
+	return report, true
+}
+func (ch *ConfigurationHandler) getValidationReport() models.ValidationReport {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.validationReport
+}
+func (ch *ConfigurationHandler) exportSnapshot() ([]byte, error) {
+	ch.mu.Lock()
+	cache := ch.cache
+	ch.mu.Unlock()
+	return models.ExportSnapshot(cache, ch.environmentID, ch.collectionID)
+}
+func (ch *ConfigurationHandler) loadSnapshot(data []byte) error {
+	configurations, metadata, err := models.ReadSnapshot(data, ch.environmentID, ch.collectionID)
+	if err != nil {
+		log.Error(messages.LoadSnapshotErr, err.Error())
+		return err
+	}
+	report, valid := ch.validateConfigurations(data, "snapshot", metadata.EnvironmentID, metadata.CollectionID)
+	if !valid {
+		return errors.New(messages.InvalidConfigurationsRejected + "snapshot")
+	}
+	var fetchedAt time.Time
+	if metadata.FetchedAt != nil {
+		fetchedAt = *metadata.FetchedAt
+	}
+	log.Info(messages.LoadSnapshot, metadata.EnvironmentID, "/", metadata.CollectionID)
+	if !ch.saveValidatedInCache(configurations, models.DataSourceSnapshot, fetchedAt, report) {
+		return errors.New(messages.LiveUpdatesPaused)
+	}
+	if ch.configurationUpdateListener != nil {
+		ch.configurationUpdateListener()
+	}
+	return nil
+}
+func (ch *ConfigurationHandler) getStatus() Status {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.cache == nil || ch.cache.FeatureMap == nil {
+		return Status{}
+	}
+	status := Status{Ready: true, Source: ch.cache.Source, FetchedAt: ch.cache.FetchedAt, PinnedVersion: ch.pinnedVersion}
+	status.Age, _ = ch.cache.Age(models.Now())
+	if ch.persistentCacheMaxAge > 0 && ch.cache.Source == models.DataSourcePersistentCache {
+		status.Stale = status.FetchedAt.IsZero() || status.Age > ch.persistentCacheMaxAge
+	}
+	if status.Stale && ch.stalenessPolicy == FailReadiness {
+		status.Ready = false
+	}
+	return status
+}
 func (ch *ConfigurationHandler) saveInCache(data []byte) {
+	ch.saveInCacheFrom(data, "", time.Time{})
+}
+
+// saveInCacheFrom saves the configurations in the cache, recording where they come from and when they were fetched.
+// The configurations are dropped while a rollback pins the cache, and saveInCacheFrom returns false.
+func (ch *ConfigurationHandler) saveInCacheFrom(data []byte, source string, fetchedAt time.Time) bool {
+	return ch.setCache(data, source, fetchedAt, false, 0)
+}
+
+// saveValidatedInCache saves the configurations in the cache like saveInCacheFrom, and keeps their validation report
+// if they are saved, so that the report of rejected or dropped configurations does not replace it.
+func (ch *ConfigurationHandler) saveValidatedInCache(data []byte, source string, fetchedAt time.Time, report models.ValidationReport) bool {
+	if !ch.saveInCacheFrom(data, source, fetchedAt) {
+		return false
+	}
+	ch.mu.Lock()
+	ch.validationReport = report
+	ch.mu.Unlock()
+	return true
+}
+
+// setCache saves the configurations in the cache. A rollback sets the pinned version, 0 unpinning the cache, along
+// with the configurations; any other write is dropped while the cache is pinned.
+func (ch *ConfigurationHandler) setCache(data []byte, source string, fetchedAt time.Time, rollback bool, pinnedVersion int) bool {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
+	if !rollback && ch.pinnedVersion != 0 {
+		log.Info(messages.LiveUpdatesPaused)
+		return false
+	}
 	configurations := models.CacheConfig{}
 	err := json.Unmarshal(data, &configurations)
 	if err != nil {
 		log.Error(messages.UnmarshalJSONErr, err)
-		return
+		return false
+	}
+	if rollback {
+		ch.pinnedVersion = pinnedVersion
+		ch.history.Pin(pinnedVersion)
 	}
 	log.Debug(configurations)
 	featureMap := make(map[string]models.Feature)
@@ -176,14 +559,150 @@ func (ch *ConfigurationHandler) saveInCache(data []byte) {
 		segmentMap[segment.GetSegmentID()] = segment
 	}
 	log.Debug(messages.SetInMemoryCache)
-	models.SetCache(featureMap, propertyMap, segmentMap)
+	models.SetCacheWithOrigin(featureMap, propertyMap, segmentMap, source, fetchedAt)
 	ch.cache = models.GetCacheInstance()
+	if ch.history != nil && ch.pinnedVersion == 0 {
+		ch.recordHistory()
+	}
+	return true
 }
-func (ch *ConfigurationHandler) updateCacheAndListener(data []byte) {
-	ch.saveInCache(data)
+
+// recordHistory records the configurations of the cache in the history, and persists the history if they are new.
+func (ch *ConfigurationHandler) recordHistory() {
+	version, recorded, err := ch.history.Record(ch.cache, ch.environmentID, ch.collectionID)
+	if err != nil {
+		log.Error(messages.HistoryErr, err)
+		return
+	}
+	if recorded {
+		log.Debug(messages.HistoryRecorded, version.Version)
+		ch.persistHistory()
+	}
+}
+
+// persistHistory persists the history in the history store, if one is set, in the background.
+func (ch *ConfigurationHandler) persistHistory() {
+	if ch.historyStore == nil {
+		return
+	}
+	store, history, environmentID, collectionID := ch.historyStore, ch.history, ch.environmentID, ch.collectionID
+	ch.persist(func() { storeHistory(store, history, environmentID, collectionID) })
+}
+
+// storeHistory persists the history in the store. The history is encoded under the lock of the store, so that the
+// last write holds the latest versions.
+func storeHistory(store utils.CacheStore, history *models.History, environmentID, collectionID string) {
+	unlock, err := store.Lock()
+	if err != nil {
+		log.Error(messages.HistoryErr, err)
+		return
+	}
+	defer unlock()
+	data, err := history.Encode(environmentID, collectionID)
+	if err == nil {
+		err = store.Save(data)
+	}
+	if err != nil {
+		log.Error(messages.HistoryErr, err)
+	}
+}
+
+// loadHistory reads the history persisted in the history store.
+func (ch *ConfigurationHandler) loadHistory() {
+	unlock, err := ch.historyStore.Lock()
+	if err != nil {
+		log.Error(messages.HistoryErr, err)
+		return
+	}
+	defer unlock()
+	data, err := ch.historyStore.Load()
+	if err != nil {
+		log.Error(messages.HistoryErr, err)
+		return
+	}
+	if data != nil {
+		if err = ch.history.Decode(data, ch.environmentID, ch.collectionID); err != nil {
+			log.Error(messages.HistoryErr, err)
+		}
+	}
+}
+
+// restorePinnedVersion serves the version that a rollback pinned before a restart, as persisted in the history store.
+func (ch *ConfigurationHandler) restorePinnedVersion() {
+	if ch.history == nil || ch.history.Pinned() == 0 {
+		return
+	}
+	pinned := ch.history.Pinned()
+	entry, _ := ch.history.Get(pinned)
+	configurations, _, err := models.ReadSnapshot(entry.Snapshot, ch.environmentID, ch.collectionID)
+	if err != nil || !ch.setCache(configurations, entry.Source, entry.FetchedAt, true, pinned) {
+		log.Error(messages.HistoryErr, "the pinned version ", pinned, " cannot be restored")
+		ch.history.Pin(0)
+		return
+	}
+	log.Warn(messages.ConfigurationPinRestored, pinned)
+}
+func (ch *ConfigurationHandler) getHistory() ([]models.ConfigurationVersion, error) {
+	if ch.history == nil {
+		return nil, errors.New(messages.HistoryDisabled)
+	}
+	return ch.history.Versions(), nil
+}
+func (ch *ConfigurationHandler) rollback(version int) error {
+	if ch.history == nil {
+		return errors.New(messages.HistoryDisabled)
+	}
+	entry, ok := ch.history.Get(version)
+	if !ok {
+		return fmt.Errorf("%s%d", messages.UnknownConfigurationVersion, version)
+	}
+	if err := ch.applyVersion(entry, version); err != nil {
+		return err
+	}
+	log.Warn(messages.ConfigurationRolledBack, version)
+	return nil
+}
+func (ch *ConfigurationHandler) resume() error {
+	if ch.getPinnedVersion() == 0 {
+		return nil
+	}
+	if latest, ok := ch.history.Latest(); ok {
+		if err := ch.applyVersion(latest, 0); err != nil {
+			return err
+		}
+	}
+	log.Info(messages.LiveUpdatesResumed)
+	if ch.liveConfigUpdateEnabled {
+		go ch.fetchFromAPI()
+	}
+	return nil
+}
+
+// applyVersion serves the configurations of a version of the history, pinned to pinnedVersion, and calls the
+// configuration update listener. A pinnedVersion of 0 unpins the configurations.
+func (ch *ConfigurationHandler) applyVersion(entry models.ConfigurationVersion, pinnedVersion int) error {
+	configurations, _, err := models.ReadSnapshot(entry.Snapshot, ch.environmentID, ch.collectionID)
+	if err != nil {
+		return err
+	}
+	if !ch.setCache(configurations, entry.Source, entry.FetchedAt, true, pinnedVersion) {
+		return errors.New(messages.UnmarshalJSONErr)
+	}
+	ch.persistHistory()
 	if ch.configurationUpdateListener != nil {
 		ch.configurationUpdateListener()
 	}
+	return nil
+}
+func (ch *ConfigurationHandler) getPinnedVersion() int {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.pinnedVersion
+}
+func (ch *ConfigurationHandler) updateCacheAndListener(data []byte) {
+	if ch.saveInCacheFrom(data, models.DataSourceService, models.Now()) && ch.configurationUpdateListener != nil {
+		ch.configurationUpdateListener()
+	}
 }
 func extractErrorMessage(err error, response *core.DetailedResponse) string {
 	if err != nil {
@@ -201,6 +720,10 @@ func extractErrorMessage(err error, response *core.DetailedResponse) string {
 	return string(response.RawResult)
 }
 func (ch *ConfigurationHandler) fetchFromAPI() {
+	if ch.getPinnedVersion() != 0 {
+		log.Info(messages.LiveUpdatesPaused)
+		return
+	}
 	if ch.isInitialized {
 		builder := core.NewRequestBuilder(core.GET)
 		builder.AddQuery("action", "sdkConfig")
@@ -237,17 +760,25 @@ func (ch *ConfigurationHandler) fetchFromAPI() {
 		if response != nil && response.StatusCode == 200 {
 			log.Info(messages.FetchAPISuccessful)
 			jsonData, _ := json.Marshal(response.Result)
+			report, valid := ch.validateConfigurations(jsonData, "fetched configurations", ch.environmentID, ch.collectionID)
+			if !valid {
+				return
+			}
 			configurations, err := models.ExtractConfigurations(jsonData, ch.environmentID, ch.collectionID)
 			if err != nil {
 				log.Error("Error occurred while reading fetched configurations - ", err.Error())
 				return
 			}
 			// asynchronously write the response to persistent volume, if enabled
-			if len(ch.persistentCacheDirectory) > 0 {
-				go utils.StoreFiles(string(jsonData), ch.persistentCacheDirectory)
+			if ch.cacheStore != nil {
+				store, content := ch.cacheStore, string(jsonData)
+				metadata := utils.CacheMetadata{FetchedAt: models.Now(), EnvironmentID: ch.environmentID, CollectionID: ch.collectionID}
+				ch.persist(func() { utils.StoreConfigurationsWithMetadata(store, content, metadata) })
 			}
 			// load the configurations in the response to cache maps
-			ch.updateCacheAndListener(configurations)
+			if ch.saveValidatedInCache(configurations, models.DataSourceService, models.Now(), report) && ch.configurationUpdateListener != nil {
+				ch.configurationUpdateListener()
+			}
 		} else {
 			if response != nil && response.StatusCode >= 400 && response.StatusCode < 499 && response.StatusCode != 429 {
 				// Do Nothing! GET "/config" failed due to a client-side error.
@@ -348,6 +879,19 @@ func (ch *ConfigurationHandler) getFeature(featureID string) (models.Feature, er
 	return models.Feature{}, errors.New(messages.ErrorInvalidFeatureID + featureID)
 
 }
+func (ch *ConfigurationHandler) getSegmentsForEntity(entityID string, entityAttributes map[string]interface{}) ([]models.SegmentMembership, error) {
+	if len(entityID) <= 0 {
+		log.Error(messages.InvalidEntityId, "GetSegmentsForEntity")
+		return nil, errors.New(messages.InvalidEntityId + "GetSegmentsForEntity")
+	}
+	ch.mu.Lock()
+	cache := ch.cache
+	ch.mu.Unlock()
+	if cache == nil {
+		return nil, errors.New(messages.InitError)
+	}
+	return models.GetSegmentsForEntity(cache.SegmentMap, entityID, entityAttributes), nil
+}
 func (ch *ConfigurationHandler) getProperties() (map[string]models.Property, error) {
 	if ch.cache == nil {
 		return nil, errors.New(messages.InitError)
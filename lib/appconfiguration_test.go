@@ -18,6 +18,7 @@ package lib
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
 	// "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
@@ -132,6 +133,73 @@ func TestSetContext(t *testing.T) {
 		t.Errorf("Test failed: Incorrect error message")
 	}
 	reset(ac)
+
+	// test persistent cache encryption with an invalid key
+	ac.Init("a", "b", "c")
+	ac.isInitialized = true
+	ac.SetContext("c1", "dev", ContextOptions{
+		PersistentCacheDirectory: "/tmp",
+		CacheEncryptionKey:       []byte("secret"),
+	})
+	assert.Equal(t, "AppConfiguration - Invalid persistent cache encryption key: invalid key length 6, expected 16, 24 or 32 bytes", hook.LastEntry().Message)
+	assert.Equal(t, false, ac.isInitializedConfig)
+	reset(ac)
+
+	// test bootstrap configurations provided twice
+	ac.Init("a", "b", "c")
+	ac.isInitialized = true
+	ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile: "flights.json",
+		BootstrapData: []byte(`{}`),
+	})
+	assert.Equal(t, "AppConfiguration - Provide only one of BootstrapFile, BootstrapData, BootstrapReader and BootstrapLayers.", hook.LastEntry().Message)
+	assert.Equal(t, false, ac.isInitializedConfig)
+	reset(ac)
+
+	// test bootstrap data of an unknown format
+	ac.Init("a", "b", "c")
+	ac.isInitialized = true
+	ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapData:   []byte(`{}`),
+		BootstrapFormat: "xml",
+	})
+	assert.Equal(t, "AppConfiguration - Invalid value provided for BootstrapFormat parameter - xml", hook.LastEntry().Message)
+	assert.Equal(t, false, ac.isInitializedConfig)
+	reset(ac)
+
+	// test a bootstrap file system without a valid path
+	ac.Init("a", "b", "c")
+	ac.isInitialized = true
+	ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile: "/saflights/flights.json",
+		BootstrapFS:   fstest.MapFS{},
+	})
+	assert.Equal(t, "AppConfiguration - Invalid value provided for BootstrapFile parameter - /saflights/flights.json", hook.LastEntry().Message)
+	assert.Equal(t, false, ac.isInitializedConfig)
+	reset(ac)
+
+	// test a bootstrap layer without configurations
+	ac.Init("a", "b", "c")
+	ac.isInitialized = true
+	ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapLayers: []BootstrapLayer{{File: "flights.json"}, {Format: "yaml"}},
+	})
+	assert.Equal(t, "AppConfiguration - Provide exactly one of File, Data and Reader in every bootstrap layer.", hook.LastEntry().Message)
+	assert.Equal(t, false, ac.isInitializedConfig)
+	reset(ac)
+}
+func TestMergeBootstrapLayers(t *testing.T) {
+	base := `{"environments":[{"name":"Dev","environment_id":"dev","features":[],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":1,"segment_rules":[]}]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`
+	config, report, err := MergeBootstrapLayers(
+		BootstrapLayer{Data: []byte(base)},
+		BootstrapLayer{Data: []byte("environments:\n  - environment_id: dev\n    properties:\n      - property_id: p1\n        value: 2\n"), Format: "yaml"},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(2), config.Environments[0].Properties[0].Value)
+	assert.Equal(t, []MergeChange{{Layer: 1, Kind: "property", ID: "p1", EnvironmentID: "dev", Action: "overridden", Fields: []string{"value"}}}, report.Changes)
+
+	_, _, err = MergeBootstrapLayers(BootstrapLayer{Data: []byte(base)}, BootstrapLayer{})
+	assert.EqualError(t, err, "bootstrap layer 1: no bootstrap configurations in the layer")
 }
 func TestGetFeature(t *testing.T) {
 	// test get feature when not initialised properly
@@ -264,3 +332,15 @@ func mockSetCache(ac *AppConfiguration) {
 	cacheInstance.PropertyMap = propertyMap
 	ac.configurationHandlerInstance.cache = cacheInstance
 }
+
+func TestSnapshotNotInitialized(t *testing.T) {
+	ac := GetInstance()
+	_, err := ac.ExportSnapshot()
+	assert.Error(t, err)
+	assert.Error(t, ac.LoadSnapshot([]byte(`{}`)))
+	status, err := ac.GetStatus()
+	assert.Error(t, err)
+	assert.False(t, status.Ready)
+	assert.False(t, ac.IsReady())
+	reset(ac)
+}
@@ -20,9 +20,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
@@ -57,7 +61,7 @@ func TestConfigHandlerSetContext(t *testing.T) {
 	})
 	assert.Equal(t, "c1", ch.collectionID)
 	assert.Equal(t, "dev", ch.environmentID)
-	assert.Equal(t, "flights.json", ch.bootstrapFile)
+	assert.Equal(t, "flights.json", ch.bootstrapLayers[0].File)
 	assert.Equal(t, false, ch.liveConfigUpdateEnabled)
 }
 
@@ -373,6 +377,42 @@ func TestConfigHandlerGetFeatures(t *testing.T) {
 	assert.Equal(t, 0, len(val))
 
 }
+func TestConfigHandlerGetSegmentsForEntity(t *testing.T) {
+	ch := GetConfigurationHandlerInstance()
+	data := `{"features":[],"properties":[],"segments":[{"name":"beta-users","segment_id":"knliu818","rules":[{"values":["ibm.com"],"operator":"contains","attribute_name":"email"}]},{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com","in.ibm.com"],"operator":"endsWith","attribute_name":"email"},{"values":["IN"],"operator":"is","attribute_name":"country"}]}]}`
+	ch.saveInCache([]byte(data))
+	memberships, err := ch.getSegmentsForEntity("user1", map[string]interface{}{"email": "alice@ibm.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(memberships))
+	assert.Equal(t, "ka761hap", memberships[0].SegmentID)
+	assert.False(t, memberships[0].Matched)
+	assert.True(t, memberships[0].Rules[0].Passed)
+	assert.False(t, memberships[0].Rules[1].AttributePresent)
+	assert.Equal(t, "knliu818", memberships[1].SegmentID)
+	assert.True(t, memberships[1].Matched)
+
+	// the memberships can be read while the configurations are updated
+	updated := make(chan struct{})
+	go func() {
+		defer close(updated)
+		for i := 0; i < 10; i++ {
+			ch.saveInCache([]byte(data))
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		memberships, err = ch.getSegmentsForEntity("user1", map[string]interface{}{"email": "alice@ibm.com"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(memberships))
+	}
+	<-updated
+
+	_, err = ch.getSegmentsForEntity("", nil)
+	assert.Equal(t, "Invalid entityId passed to GetSegmentsForEntity", err.Error())
+
+	ch.cache = nil
+	_, err = ch.getSegmentsForEntity("user1", nil)
+	assert.NotNil(t, err)
+}
 func wsEndpoint(w http.ResponseWriter, r *http.Request) {
 	var upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
@@ -393,5 +433,555 @@ func wsEndpoint(w http.ResponseWriter, r *http.Request) {
 
 }
 func resetConfigurationHandler(ch *ConfigurationHandler) {
+	ch.waitPersisted()
 	ch.cache = new(models.Cache)
+	ch.validationReport = models.ValidationReport{}
+}
+
+func TestLoadDataValidatesConfigurations(t *testing.T) {
+	mockLogger()
+	invalidBootstrap := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":true,"order":1,"rollout_percentage":"half"}],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	bootstrapFile := filepath.Join(t.TempDir(), "bootstrap.json")
+	assert.Nil(t, os.WriteFile(bootstrapFile, []byte(invalidBootstrap), 0644))
+
+	// invalid configurations are loaded by default, and the errors are reported
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           bootstrapFile,
+		LiveConfigUpdateEnabled: false,
+	})
+	ch.loadData()
+	assert.Equal(t, 1, len(ch.cache.FeatureMap))
+	report := ch.getValidationReport()
+	assert.False(t, report.IsValid())
+	assert.Equal(t, `error: feature f1 (environment dev): segment_rules[0].rollout_percentage "half" is not a number`, report.Errors[0].String())
+	resetConfigurationHandler(ch)
+
+	// invalid configurations are rejected, and the previous snapshot is kept
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:               bootstrapFile,
+		LiveConfigUpdateEnabled:     false,
+		RejectInvalidConfigurations: true,
+	})
+	ch.saveInCache([]byte(`{"features":[{"name":"F0","feature_id":"f0","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`))
+	ch.loadData()
+	assert.Equal(t, "AppConfiguration - Rejecting invalid configurations, the previous configurations remain in use. Source: bootstrap file", hook.LastEntry().Message)
+	assert.Equal(t, 1, len(ch.cache.FeatureMap))
+	_, ok := ch.cache.FeatureMap["f0"]
+	assert.True(t, ok)
+	// the report of the rejected configurations does not replace the report of the configurations in use
+	assert.Empty(t, ch.getValidationReport().Errors)
+	resetConfigurationHandler(ch)
+
+	// the errors of the other environments do not reject the configurations of the client
+	prodEnvironment := `{"name":"Prod","environment_id":"prod","features":[{"name":"F2","feature_id":"f2","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[]}`
+	assert.Nil(t, os.WriteFile(bootstrapFile, []byte(strings.Replace(invalidBootstrap, `"properties":[]}]`, `"properties":[]},`+prodEnvironment+`]`, 1)), 0644))
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "prod", ContextOptions{
+		BootstrapFile:               bootstrapFile,
+		LiveConfigUpdateEnabled:     false,
+		RejectInvalidConfigurations: true,
+	})
+	ch.loadData()
+	_, ok = ch.cache.FeatureMap["f2"]
+	assert.True(t, ok)
+	report = ch.getValidationReport()
+	assert.True(t, report.IsValid())
+	resetConfigurationHandler(ch)
+}
+func TestLoadDataFromYAMLBootstrapFile(t *testing.T) {
+	mockLogger()
+	bootstrap := `environments:
+  - name: Dev
+    environment_id: dev
+    features:
+      - name: F1
+        feature_id: f1
+        type: BOOLEAN
+        enabled_value: true
+        disabled_value: false
+        segment_rules: []
+        enabled: true
+    properties: []
+collections:
+  - name: C1
+    collection_id: c1
+segments: []
+`
+	bootstrapFile := filepath.Join(t.TempDir(), "bootstrap.yaml")
+	assert.Nil(t, os.WriteFile(bootstrapFile, []byte(bootstrap), 0644))
+
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           bootstrapFile,
+		LiveConfigUpdateEnabled: false,
+	})
+	ch.loadData()
+	assert.Equal(t, 1, len(ch.cache.FeatureMap))
+	assert.True(t, ch.cache.FeatureMap["f1"].EnabledValue.(bool))
+	resetConfigurationHandler(ch)
+
+	// a type mismatch is reported with its line
+	assert.Nil(t, os.WriteFile(bootstrapFile, []byte(strings.Replace(bootstrap, "enabled: true\n    properties", "enabled: maybe\n    properties", 1)), 0644))
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           bootstrapFile,
+		LiveConfigUpdateEnabled: false,
+	})
+	ch.loadData()
+	assert.Equal(t, 0, len(ch.cache.FeatureMap))
+	var logged []string
+	for _, entry := range hook.AllEntries() {
+		logged = append(logged, entry.Message)
+	}
+	assert.Contains(t, logged, "AppConfiguration - Error occurred while reading bootstrap configurations - "+bootstrapFile+": yaml: line 11: environments.0.features.0.enabled: cannot use string \"maybe\" as bool")
+	resetConfigurationHandler(ch)
+}
+func TestLoadDataFromBootstrapSources(t *testing.T) {
+	mockLogger()
+	bootstrap := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`
+	modTime := time.Now().Add(-time.Hour).Round(time.Second)
+	sources := map[string]ContextOptions{
+		"data":   {BootstrapData: []byte(bootstrap)},
+		"reader": {BootstrapReader: strings.NewReader(bootstrap)},
+		"fs":     {BootstrapFS: fstest.MapFS{"config/flights.json": {Data: []byte(bootstrap), ModTime: modTime}}, BootstrapFile: "config/flights.json"},
+		"yaml":   {BootstrapData: []byte("environments:\n  - name: Dev\n    environment_id: dev\n    features:\n      - {name: F1, feature_id: f1, type: BOOLEAN, enabled_value: true, disabled_value: false, segment_rules: [], enabled: true}\n    properties: []\ncollections:\n  - {name: C1, collection_id: c1}\nsegments: []\n"), BootstrapFormat: "yaml"},
+	}
+	for name, options := range sources {
+		ch := GetConfigurationHandlerInstance()
+		ch.SetContext("c1", "dev", options)
+		ch.loadData()
+		assert.Equal(t, 1, len(ch.cache.FeatureMap), name)
+		assert.Equal(t, models.DataSourceBootstrap, ch.cache.Source, name)
+		if name == "fs" {
+			assert.True(t, ch.cache.FetchedAt.Equal(modTime))
+		}
+		// the reader is read once, the configurations are loaded again from the data read
+		ch.loadData()
+		assert.Equal(t, 1, len(ch.cache.FeatureMap), name)
+		resetConfigurationHandler(ch)
+	}
+
+	// an unreadable file of the file system is reported
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{BootstrapFS: fstest.MapFS{}, BootstrapFile: "flights.json"})
+	ch.loadData()
+	assert.Equal(t, "AppConfiguration - Error occurred while reading bootstrap configurations - no data matching for environment id: dev", hook.LastEntry().Message)
+	resetConfigurationHandler(ch)
+}
+func TestLoadDataFromBootstrapLayers(t *testing.T) {
+	mockLogger()
+	base := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`
+	directory := t.TempDir()
+	baseFile := filepath.Join(directory, "base.json")
+	assert.Nil(t, os.WriteFile(baseFile, []byte(base), 0644))
+	regionFile := filepath.Join(directory, "region.yaml")
+	assert.Nil(t, os.WriteFile(regionFile, []byte("environments:\n  - environment_id: dev\n    features:\n      - feature_id: f1\n        enabled: false\n"), 0644))
+
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{BootstrapLayers: []BootstrapLayer{
+		{File: baseFile},
+		{File: regionFile},
+		{Reader: strings.NewReader(`{"environments":[{"environment_id":"dev","features":[{"feature_id":"f1","enabled_value":false}]}]}`)},
+	}})
+	ch.loadData()
+	assert.Equal(t, 1, len(ch.cache.FeatureMap))
+	assert.False(t, ch.cache.FeatureMap["f1"].Enabled)
+	assert.False(t, ch.cache.FeatureMap["f1"].EnabledValue.(bool))
+	report := ch.getMergeReport()
+	assert.Equal(t, "layer 1: overridden feature f1 (environment dev): enabled\nlayer 2: overridden feature f1 (environment dev): enabled_value", report.String())
+	resetConfigurationHandler(ch)
+
+	// a layer that cannot be read fails the whole bootstrap
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{BootstrapLayers: []BootstrapLayer{
+		{File: baseFile},
+		{File: filepath.Join(directory, "missing.json")},
+	}})
+	ch.loadData()
+	assert.Equal(t, 0, len(ch.cache.FeatureMap))
+	var logged []string
+	for _, entry := range hook.AllEntries() {
+		logged = append(logged, entry.Message)
+	}
+	assert.Contains(t, logged, "AppConfiguration - Error occurred while reading bootstrap configurations - bootstrap layer 1: open "+filepath.Join(directory, "missing.json")+": no such file or directory")
+	resetConfigurationHandler(ch)
+}
+func TestWatchBootstrapFile(t *testing.T) {
+	mockLogger()
+	bootstrap := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`
+	dir := t.TempDir()
+	// the layout of a Kubernetes ConfigMap volume, updated by swapping the ..data link
+	writeVersion := func(version, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, version), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, version, "flights.json"), []byte(content), 0644))
+		assert.Nil(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("v1", bootstrap)
+	bootstrapFile := filepath.Join(dir, "flights.json")
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "flights.json"), bootstrapFile))
+
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:          bootstrapFile,
+		WatchBootstrapFile:     true,
+		BootstrapWatchInterval: 10 * time.Millisecond,
+	})
+	updates := make(chan struct{}, 10)
+	ch.configurationUpdateListener = func() { updates <- struct{}{} }
+	ch.loadData()
+	assert.True(t, ch.cache.FeatureMap["f1"].Enabled)
+
+	writeVersion("v2", strings.Replace(bootstrap, `"enabled":true}`, `"enabled":false}`, 1))
+	select {
+	case <-updates:
+	case <-time.After(time.Second):
+		t.Fatal("Test failed: the bootstrap file change is not loaded")
+	}
+	ch.mu.Lock()
+	assert.False(t, ch.cache.FeatureMap["f1"].Enabled)
+	ch.mu.Unlock()
+
+	// configurations that cannot be read are not loaded
+	writeVersion("v3", `{"environments":[`)
+	assert.Eventually(t, func() bool {
+		return hook.LastEntry().Message == "AppConfiguration - Error occurred while reading bootstrap configurations - failed to parse configurations: unexpected end of JSON input"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, len(updates))
+	ch.mu.Lock()
+	assert.Equal(t, 1, len(ch.cache.FeatureMap))
+	ch.mu.Unlock()
+
+	// the listener can set the context, which stops the watch
+	contextSet := make(chan struct{})
+	ch.mu.Lock()
+	ch.configurationUpdateListener = func() {
+		ch.SetContext("c1", "dev", ContextOptions{BootstrapFile: bootstrapFile})
+		close(contextSet)
+	}
+	ch.mu.Unlock()
+	writeVersion("v4", bootstrap)
+	select {
+	case <-contextSet:
+	case <-time.After(time.Second):
+		t.Fatal("Test failed: the listener cannot set the context")
+	}
+	assert.Nil(t, ch.bootstrapWatcher)
+
+	// the watch stops with the next context
+	ch.SetContext("c1", "dev", ContextOptions{BootstrapFile: bootstrapFile, WatchBootstrapFile: true, LiveConfigUpdateEnabled: true})
+	assert.Nil(t, ch.bootstrapWatcher)
+	assert.Equal(t, "AppConfiguration - WatchBootstrapFile is ignored when LiveConfigUpdateEnabled is true, the configurations are updated by the server.", hook.LastEntry().Message)
+	ch.configurationUpdateListener = nil
+	resetConfigurationHandler(ch)
+}
+func TestLoadDataFromCacheStore(t *testing.T) {
+	mockLogger()
+	bootstrap := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`
+	bootstrapFile := filepath.Join(t.TempDir(), "bootstrap.json")
+	assert.Nil(t, os.WriteFile(bootstrapFile, []byte(bootstrap), 0644))
+	store := NewMemoryCacheStore()
+
+	// the bootstrap configurations are written to the cache store
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           bootstrapFile,
+		LiveConfigUpdateEnabled: false,
+		CacheStore:              store,
+	})
+	ch.loadData()
+	assert.Equal(t, 1, len(ch.cache.FeatureMap))
+	assert.Eventually(t, func() bool {
+		data, _ := store.Load()
+		return data != nil
+	}, time.Second, 10*time.Millisecond)
+	resetConfigurationHandler(ch)
+
+	// and read back from it on the next start
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{
+		LiveConfigUpdateEnabled: false,
+		CacheStore:              store,
+	})
+	ch.loadData()
+	_, ok := ch.cache.FeatureMap["f1"]
+	assert.True(t, ok)
+	assert.Equal(t, "AppConfiguration - Reading configurations from persistent cache: *utils.MemoryCacheStore", hook.Entries[len(hook.Entries)-1].Message)
+	resetConfigurationHandler(ch)
+
+	// but not for another environment
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "prod", ContextOptions{
+		LiveConfigUpdateEnabled: false,
+		CacheStore:              store,
+	})
+	ch.loadData()
+	assert.Equal(t, 0, len(ch.cache.FeatureMap))
+	resetConfigurationHandler(ch)
+
+	// the persistent cache directory is a file store
+	ch.SetContext("c1", "dev", ContextOptions{PersistentCacheDirectory: "/tmp"})
+	assert.Equal(t, "/tmp/appconfiguration.json", describeCacheStore(ch.cacheStore))
+	ch.SetContext("c1", "dev", ContextOptions{})
+	assert.Nil(t, ch.cacheStore)
+
+	// and is encrypted when a key is set
+	ch.SetContext("c1", "dev", ContextOptions{PersistentCacheDirectory: "/tmp", CacheEncryptionKey: make([]byte, 32)})
+	assert.Equal(t, "/tmp/appconfiguration.json (encrypted)", describeCacheStore(ch.cacheStore))
+	resetConfigurationHandler(ch)
+
+	// an encrypted store round-trips the configurations
+	ch = GetConfigurationHandlerInstance()
+	ring, err := NewKeyRing("k1", map[string][]byte{"k1": make([]byte, 16)})
+	assert.Nil(t, err)
+	store = NewMemoryCacheStore()
+	ch.SetContext("c1", "dev", ContextOptions{BootstrapFile: bootstrapFile, CacheStore: store, CacheKeyProvider: ring})
+	ch.loadData()
+	assert.Eventually(t, func() bool {
+		data, _ := store.Load()
+		return data != nil
+	}, time.Second, 10*time.Millisecond)
+	data, _ := store.Load()
+	assert.NotContains(t, string(data), `"feature_id"`)
+	resetConfigurationHandler(ch)
+	ch = GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{CacheStore: store, CacheKeyProvider: ring})
+	ch.loadData()
+	_, ok = ch.cache.FeatureMap["f1"]
+	assert.True(t, ok)
+	resetConfigurationHandler(ch)
+}
+
+func TestPersistentCacheStaleness(t *testing.T) {
+	mockLogger()
+	persistent := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`
+	bootstrap := strings.Replace(persistent, `"feature_id":"f1"`, `"feature_id":"f2"`, 1)
+	bootstrapFile := filepath.Join(t.TempDir(), "bootstrap.json")
+	assert.Nil(t, os.WriteFile(bootstrapFile, []byte(bootstrap), 0644))
+	newStore := func() CacheStore {
+		store := NewMemoryCacheStore()
+		utils.StoreConfigurationsWithMetadata(store, persistent, utils.CacheMetadata{
+			FetchedAt:     time.Now().Add(-48 * time.Hour),
+			EnvironmentID: "dev",
+			CollectionID:  "c1",
+		})
+		return store
+	}
+
+	// a stale persistent cache is served with a warning by default
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{CacheStore: newStore(), PersistentCacheMaxAge: 24 * time.Hour})
+	ch.loadData()
+	assert.Equal(t, "AppConfiguration - Serving a stale persistent cache, fetched 48h0m0s ago", hook.Entries[len(hook.Entries)-1].Message)
+	status := ch.getStatus()
+	assert.True(t, status.Ready)
+	assert.True(t, status.Stale)
+	assert.Equal(t, DataSourcePersistentCache, status.Source)
+	assert.InDelta(t, float64(48*time.Hour), float64(status.Age), float64(time.Minute))
+
+	// and the evaluations report the age of the configurations
+	feature := ch.cache.FeatureMap["f1"]
+	details := feature.GetEvaluationDetails("user1", EvaluationOptions{})
+	assert.Equal(t, DataSourcePersistentCache, details.DataSource)
+	assert.InDelta(t, float64(48*time.Hour), float64(details.DataAge), float64(time.Minute))
+	resetConfigurationHandler(ch)
+
+	// a fresh persistent cache is not stale
+	ch.SetContext("c1", "dev", ContextOptions{CacheStore: newStore(), PersistentCacheMaxAge: 72 * time.Hour, StalenessPolicy: FailReadiness})
+	ch.loadData()
+	assert.True(t, ch.getStatus().Ready)
+	assert.False(t, ch.getStatus().Stale)
+	resetConfigurationHandler(ch)
+
+	// a stale persistent cache fails the readiness
+	ch.SetContext("c1", "dev", ContextOptions{CacheStore: newStore(), PersistentCacheMaxAge: 24 * time.Hour, StalenessPolicy: FailReadiness})
+	ch.loadData()
+	_, ok := ch.cache.FeatureMap["f1"]
+	assert.True(t, ok)
+	assert.False(t, ch.getStatus().Ready)
+
+	// until configurations are fetched from the service
+	configurations, _ := models.ExtractConfigurations([]byte(persistent), "dev", "c1")
+	ch.updateCacheAndListener(configurations)
+	status = ch.getStatus()
+	assert.True(t, status.Ready)
+	assert.False(t, status.Stale)
+	assert.Equal(t, DataSourceService, status.Source)
+	resetConfigurationHandler(ch)
+
+	// or the bootstrap file is served instead
+	ch.SetContext("c1", "dev", ContextOptions{CacheStore: newStore(), BootstrapFile: bootstrapFile, PersistentCacheMaxAge: 24 * time.Hour, StalenessPolicy: ServeBootstrap})
+	ch.loadData()
+	_, ok = ch.cache.FeatureMap["f2"]
+	assert.True(t, ok)
+	status = ch.getStatus()
+	assert.True(t, status.Ready)
+	assert.Equal(t, DataSourceBootstrap, status.Source)
+	resetConfigurationHandler(ch)
+
+	// the age is measured with the clock of the context, as in the evaluations
+	clock := time.Now().Add(-47 * time.Hour)
+	ch.SetContext("c1", "dev", ContextOptions{CacheStore: newStore(), PersistentCacheMaxAge: 24 * time.Hour, StalenessPolicy: FailReadiness, Clock: func() time.Time { return clock }})
+	ch.loadData()
+	status = ch.getStatus()
+	assert.True(t, status.Ready)
+	assert.False(t, status.Stale)
+	assert.InDelta(t, float64(time.Hour), float64(status.Age), float64(time.Minute))
+	feature = ch.cache.FeatureMap["f1"]
+	assert.Equal(t, status.Age, feature.GetEvaluationDetails("user1", EvaluationOptions{}).DataAge)
+	models.SetClock(nil)
+	resetConfigurationHandler(ch)
+
+	// no configurations are served before they are loaded
+	ch.cache = nil
+	assert.False(t, ch.getStatus().Ready)
+	resetConfigurationHandler(ch)
+}
+
+func TestSnapshotExportAndLoad(t *testing.T) {
+	mockLogger()
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{})
+	ch.saveInCacheFrom([]byte(`{"features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`), models.DataSourceService, time.Now())
+	snapshot, err := ch.exportSnapshot()
+	assert.Nil(t, err)
+
+	// the snapshot replaces the configurations, and the listener is called
+	ch.saveInCache([]byte(`{"features":[],"properties":[],"segments":[]}`))
+	calls := 0
+	ch.configurationUpdateListener = func() { calls++ }
+	assert.Nil(t, ch.loadSnapshot(snapshot))
+	assert.Equal(t, 1, calls)
+	_, ok := ch.cache.FeatureMap["f1"]
+	assert.True(t, ok)
+	assert.Equal(t, DataSourceSnapshot, ch.getStatus().Source)
+
+	// an invalid snapshot keeps the configurations
+	assert.EqualError(t, ch.loadSnapshot([]byte(`{"metadata":{"environment_id":"prod","collection_id":"c1"}}`)), "no data matching for environment id: prod")
+	assert.Equal(t, "AppConfiguration - Error occurred while reading the snapshot - no data matching for environment id: prod", hook.LastEntry().Message)
+	assert.Equal(t, 1, calls)
+	_, ok = ch.cache.FeatureMap["f1"]
+	assert.True(t, ok)
+	ch.configurationUpdateListener = nil
+	resetConfigurationHandler(ch)
+}
+
+func TestHistoryRollbackAndResume(t *testing.T) {
+	mockLogger()
+	configuration := func(value string) []byte {
+		return []byte(`{"features":[{"name":"F1","feature_id":"f1","type":"STRING","format":"TEXT","enabled_value":"` + value + `","disabled_value":"off","segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`)
+	}
+	value := func(ch *ConfigurationHandler) interface{} {
+		return ch.cache.FeatureMap["f1"].EnabledValue
+	}
+
+	// the history is disabled by default
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{})
+	_, err := ch.getHistory()
+	assert.EqualError(t, err, "The configuration history is disabled. Set the HistorySize of the ContextOptions.")
+	assert.NotNil(t, ch.rollback(1))
+
+	store := NewMemoryCacheStore()
+	ch.SetContext("c1", "dev", ContextOptions{HistorySize: 3, HistoryStore: store})
+	ch.updateCacheAndListener(configuration("a"))
+	ch.updateCacheAndListener(configuration("b"))
+	ch.updateCacheAndListener(configuration("b"))
+	versions, err := ch.getHistory()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(versions))
+	assert.Equal(t, DataSourceService, versions[1].Source)
+	assert.Nil(t, ch.resume())
+
+	// a rollback pins a previous version, and pauses the updates
+	calls := 0
+	ch.configurationUpdateListener = func() { calls++ }
+	assert.EqualError(t, ch.rollback(7), "No configurations in the history for version 7")
+	assert.Nil(t, ch.rollback(1))
+	assert.Equal(t, "AppConfiguration - Rolled back to the configurations of version 1", hook.LastEntry().Message)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "a", value(ch))
+	assert.Equal(t, 1, ch.getStatus().PinnedVersion)
+	ch.fetchFromAPI()
+	assert.Equal(t, "AppConfiguration - Configuration updates are paused by a rollback, call Resume to resume them.", hook.LastEntry().Message)
+	ch.loadData()
+	assert.Equal(t, "a", value(ch))
+	versions, _ = ch.getHistory()
+	assert.Equal(t, 2, len(versions))
+
+	// resuming serves the latest version again
+	assert.Nil(t, ch.resume())
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "b", value(ch))
+	assert.Equal(t, 0, ch.getStatus().PinnedVersion)
+	ch.configurationUpdateListener = nil
+
+	// the history is restored from its store
+	assert.Eventually(t, func() bool {
+		data, _ := store.Load()
+		return strings.Contains(string(data), `"version":2`)
+	}, time.Second, 10*time.Millisecond)
+	ch.SetContext("c1", "dev", ContextOptions{HistorySize: 3, HistoryStore: store})
+	versions, _ = ch.getHistory()
+	assert.Equal(t, 2, len(versions))
+	assert.Nil(t, ch.rollback(1))
+	assert.Equal(t, "a", value(ch))
+
+	// so is the rollback, until Resume
+	ch.SetContext("c1", "dev", ContextOptions{HistorySize: 3, HistoryStore: store})
+	assert.Equal(t, "AppConfiguration - Restored the rollback to the configurations of version 1", hook.LastEntry().Message)
+	assert.Equal(t, 1, ch.getPinnedVersion())
+	assert.Equal(t, "a", value(ch))
+	ch.loadData()
+	assert.Equal(t, "a", value(ch))
+	assert.Nil(t, ch.resume())
+	assert.Equal(t, "b", value(ch))
+	ch.SetContext("c1", "dev", ContextOptions{HistorySize: 3, HistoryStore: store})
+	assert.Equal(t, 0, ch.getPinnedVersion())
+
+	ch.SetContext("c1", "dev", ContextOptions{})
+	assert.Equal(t, 0, ch.getPinnedVersion())
+	resetConfigurationHandler(ch)
+}
+func TestRollbackDuringFetch(t *testing.T) {
+	mockLogger()
+	requested, release := make(chan struct{}), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(200)
+		fmt.Fprintf(w, "%s", `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"STRING","format":"TEXT","enabled_value":"live","disabled_value":"off","segment_rules":[],"enabled":true}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[]}`)
+	}))
+	defer ts.Close()
+
+	ch := GetConfigurationHandlerInstance()
+	ch.SetContext("c1", "dev", ContextOptions{HistorySize: 3})
+	ch.urlBuilder.SetBaseServiceURL(ts.URL)
+	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
+	ch.updateCacheAndListener([]byte(`{"features":[{"name":"F1","feature_id":"f1","type":"STRING","format":"TEXT","enabled_value":"a","disabled_value":"off","segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`))
+	ch.updateCacheAndListener([]byte(`{"features":[{"name":"F1","feature_id":"f1","type":"STRING","format":"TEXT","enabled_value":"b","disabled_value":"off","segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`))
+
+	// a fetch waiting on the response when the rollback happens does not undo it
+	calls := 0
+	done := make(chan struct{})
+	go func() {
+		ch.fetchFromAPI()
+		close(done)
+	}()
+	<-requested
+	assert.Nil(t, ch.rollback(1))
+	ch.configurationUpdateListener = func() { calls++ }
+	close(release)
+	<-done
+	assert.Equal(t, "a", ch.cache.FeatureMap["f1"].EnabledValue)
+	assert.Equal(t, 1, ch.getStatus().PinnedVersion)
+	assert.Equal(t, 0, calls)
+	versions, _ := ch.getHistory()
+	assert.Equal(t, 2, len(versions))
+	ch.configurationUpdateListener = nil
+	ch.SetContext("c1", "dev", ContextOptions{})
+	resetConfigurationHandler(ch)
 }
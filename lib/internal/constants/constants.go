@@ -25,8 +25,11 @@ const DefaultEntityID = "$$null$$"
 // DefaultUsageLimit : Default Usage Limit
 const DefaultUsageLimit = 10
 
+// SDKVersion : Version of the SDK
+const SDKVersion = "0.5.9"
+
 // UserAgent specifies the user agent name
-const UserAgent = "appconfiguration-go-sdk/0.5.9"
+const UserAgent = "appconfiguration-go-sdk/" + SDKVersion
 
 // ConfigurationFile : Name of file to which configurations will be written
 const ConfigurationFile = "appconfiguration.json"
@@ -36,3 +39,6 @@ const MaxNumberOfRetries = 3
 
 // MaxRetryInterval : Maximum duration between successive retries (in seconds)
 const MaxRetryInterval = 30
+
+// DefaultBootstrapWatchInterval : Default duration between successive checks of the watched bootstrap files (in seconds)
+const DefaultBootstrapWatchInterval = 5
@@ -67,6 +67,9 @@ const ConfigurationUpdateListenerMethodError = "Configuration update listener sh
 // InvalidEntityId : InvalidEntityId const
 const InvalidEntityId = "Invalid entityId passed to "
 
+// InvalidEntity : InvalidEntity const
+const InvalidEntity = "Invalid entity passed to "
+
 // ConfigurationHandlerInitError : ConfigurationHandlerInitError const
 const ConfigurationHandlerInitError = "Invalid action in ConfigurationHandler. You can perform this action only after a successful initialization. Check the initialization section for errors."
 
@@ -264,3 +267,108 @@ const InvalidSecretManagerMessage = "Secret Manager object is either invalid or
 
 // InvalidSecretID : InvalidSecretIdMessage const
 const InvalidSecretID = "Secret Id is either invalid or empty."
+
+// ConfigurationValidationIssue : ConfigurationValidationIssue const
+const ConfigurationValidationIssue = "Configuration validation issue in "
+
+// InvalidConfigurationsRejected : InvalidConfigurationsRejected const
+const InvalidConfigurationsRejected = "Rejecting invalid configurations, the previous configurations remain in use. Source: "
+
+// BucketingAttributeMissing : BucketingAttributeMissing const
+const BucketingAttributeMissing = "Bucketing attribute not found in the entity attributes, bucketing by entity id instead: "
+
+// AssignmentStoreError : AssignmentStoreError const
+const AssignmentStoreError = "Failed to access the sticky assignment of the entity, evaluating the feature flag instead: "
+
+// ExposureSinkError : ExposureSinkError const
+const ExposureSinkError = "Failed to emit the exposure event: "
+
+// PrerequisiteCycle : PrerequisiteCycle const
+const PrerequisiteCycle = "Cyclic feature flag prerequisites, the prerequisite is not met: "
+
+// PrerequisiteNotFound : PrerequisiteNotFound const
+const PrerequisiteNotFound = "Prerequisite feature flag not found or invalid, the prerequisite is not met: "
+
+// PersistentCacheCorrupted : PersistentCacheCorrupted const
+const PersistentCacheCorrupted = "The persistent cache is corrupted: "
+
+// PersistentCacheContextMismatch : PersistentCacheContextMismatch const
+const PersistentCacheContextMismatch = "The persistent cache was written for another environment or collection, ignoring it: "
+
+// PersistentCacheBackupUsed : PersistentCacheBackupUsed const
+const PersistentCacheBackupUsed = "Falling back to the previous persistent cache."
+
+// InvalidCacheEncryptionKey : InvalidCacheEncryptionKey const
+const InvalidCacheEncryptionKey = "Invalid persistent cache encryption key: "
+
+// PersistentCacheStale : PersistentCacheStale const
+const PersistentCacheStale = "Serving a stale persistent cache, fetched "
+
+// PersistentCacheStaleIgnored : PersistentCacheStaleIgnored const
+const PersistentCacheStaleIgnored = "Ignoring a stale persistent cache, fetched "
+
+// LoadSnapshot : LoadSnapshot const
+const LoadSnapshot = "Loading the configurations of the snapshot of "
+
+// LoadSnapshotErr : LoadSnapshotErr const
+const LoadSnapshotErr = "Error occurred while reading the snapshot - "
+
+// HistoryErr : HistoryErr const
+const HistoryErr = "Error occurred while recording the configuration history - "
+
+// HistoryRecorded : HistoryRecorded const
+const HistoryRecorded = "Recorded the configurations as version "
+
+// HistoryDisabled : HistoryDisabled const
+const HistoryDisabled = "The configuration history is disabled. Set the HistorySize of the ContextOptions."
+
+// UnknownConfigurationVersion : UnknownConfigurationVersion const
+const UnknownConfigurationVersion = "No configurations in the history for version "
+
+// ConfigurationRolledBack : ConfigurationRolledBack const
+const ConfigurationRolledBack = "Rolled back to the configurations of version "
+
+// LiveUpdatesPaused : LiveUpdatesPaused const
+const LiveUpdatesPaused = "Configuration updates are paused by a rollback, call Resume to resume them."
+
+// LiveUpdatesResumed : LiveUpdatesResumed const
+const LiveUpdatesResumed = "Configuration updates are resumed."
+
+// ReadBootstrapData : ReadBootstrapData const
+const ReadBootstrapData = "Reading configurations from the bootstrap data."
+
+// ReadBootstrapReaderErr : ReadBootstrapReaderErr const
+const ReadBootstrapReaderErr = "Error occurred while reading the BootstrapReader - "
+
+// MultipleBootstrapSources : MultipleBootstrapSources const
+const MultipleBootstrapSources = "Provide only one of BootstrapFile, BootstrapData, BootstrapReader and BootstrapLayers."
+
+// InvalidBootstrapFormat : InvalidBootstrapFormat const
+const InvalidBootstrapFormat = "Invalid value provided for BootstrapFormat parameter"
+
+// InvalidBootstrapLayer : InvalidBootstrapLayer const
+const InvalidBootstrapLayer = "Provide exactly one of File, Data and Reader in every bootstrap layer."
+
+// EmptyBootstrapLayer : EmptyBootstrapLayer const
+const EmptyBootstrapLayer = "no bootstrap configurations in the layer"
+
+// BootstrapLayersMerged : BootstrapLayersMerged const
+const BootstrapLayersMerged = "Merged the bootstrap layers, changes over the first layer: "
+
+// WatchFileErr : WatchFileErr const
+const WatchFileErr = "Error occurred while watching the file - "
+
+// BootstrapFileChanged : BootstrapFileChanged const
+const BootstrapFileChanged = "The bootstrap files changed, reloading the bootstrap configurations."
+
+// NoBootstrapFileToWatch : NoBootstrapFileToWatch const
+const NoBootstrapFileToWatch = "WatchBootstrapFile is ignored, no bootstrap file of the file system to watch."
+
+// WatchBootstrapFileLive : WatchBootstrapFileLive const
+const WatchBootstrapFileLive = "WatchBootstrapFile is ignored when LiveConfigUpdateEnabled is true, the configurations are updated by the server."
+
+// AssignmentStoreFlushErr : AssignmentStoreFlushErr const
+const AssignmentStoreFlushErr = "Error occurred while writing the sticky assignments - "
+
+// ConfigurationPinRestored : ConfigurationPinRestored const
+const ConfigurationPinRestored = "Restored the rollback to the configurations of version "
@@ -0,0 +1,210 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// Assignment : the value a feature flag has served to an entity.
+type Assignment struct {
+	Value      interface{} `json:"value"`
+	SegmentID  string      `json:"segment_id,omitempty"`
+	VariantKey string      `json:"variant_key,omitempty"`
+	AssignedAt time.Time   `json:"assigned_at"`
+}
+
+// AssignmentStore : remembers the values served to the entities, so that an entity keeps its value when the
+// rollout percentages, the variant weights or the segment rules of a feature flag change.
+//
+// Only the entities that are served the enabled value, a variant or a segment override value are stored. Entities
+// excluded by a rollout are not, so that raising the rollout percentage still includes them.
+// A disabled feature flag always serves its disabled value, regardless of the stored assignments.
+type AssignmentStore interface {
+	// Get returns the assignment of the entity for the feature flag, if any.
+	Get(featureID, entityID string) (Assignment, bool, error)
+	// Set stores the assignment of the entity for the feature flag.
+	Set(featureID, entityID string, assignment Assignment) error
+	// Reset removes the assignment of the entity for the feature flag.
+	// An empty entityID removes the assignments of all the entities of the feature flag.
+	Reset(featureID, entityID string) error
+}
+
+var assignmentStore AssignmentStore
+var assignmentStoreMu sync.RWMutex
+
+// SetAssignmentStore : Set the store consulted and updated by the feature flag evaluations. nil disables sticky assignments.
+func SetAssignmentStore(store AssignmentStore) {
+	assignmentStoreMu.Lock()
+	defer assignmentStoreMu.Unlock()
+	assignmentStore = store
+}
+
+// GetAssignmentStore : Get the store of the sticky assignments, nil if none is set.
+func GetAssignmentStore() AssignmentStore {
+	assignmentStoreMu.RLock()
+	defer assignmentStoreMu.RUnlock()
+	return assignmentStore
+}
+
+// InMemoryAssignmentStore : an AssignmentStore that keeps the assignments in memory for the lifetime of the process.
+type InMemoryAssignmentStore struct {
+	mu          sync.RWMutex
+	assignments map[string]map[string]Assignment // featureID -> entityID -> assignment
+}
+
+// NewInMemoryAssignmentStore : Create an empty in-memory assignment store
+func NewInMemoryAssignmentStore() *InMemoryAssignmentStore {
+	return &InMemoryAssignmentStore{assignments: make(map[string]map[string]Assignment)}
+}
+
+// Get : Get the assignment of the entity for the feature flag
+func (s *InMemoryAssignmentStore) Get(featureID, entityID string) (Assignment, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	assignment, ok := s.assignments[featureID][entityID]
+	return assignment, ok, nil
+}
+
+// Set : Store the assignment of the entity for the feature flag
+func (s *InMemoryAssignmentStore) Set(featureID, entityID string, assignment Assignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.assignments[featureID]; !ok {
+		s.assignments[featureID] = make(map[string]Assignment)
+	}
+	s.assignments[featureID][entityID] = assignment
+	return nil
+}
+
+// Reset : Remove the assignment of the entity, or of all the entities if entityID is empty, for the feature flag
+func (s *InMemoryAssignmentStore) Reset(featureID, entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(entityID) == 0 {
+		delete(s.assignments, featureID)
+	} else {
+		delete(s.assignments[featureID], entityID)
+	}
+	return nil
+}
+
+// assignmentFlushDelay is the time the FileAssignmentStore waits after a change before writing the assignments, so
+// that the changes of a burst of evaluations are written at once.
+var assignmentFlushDelay = time.Second
+
+// FileAssignmentStore : an AssignmentStore that keeps the assignments in memory and persists them to a JSON file,
+// so that they survive application restarts. The changes are written in the background, shortly after they are made,
+// so that the evaluations never wait for the disk; call Flush to write them before the application exits.
+type FileAssignmentStore struct {
+	InMemoryAssignmentStore
+	path      string
+	persistMu sync.Mutex // held from the marshalling of the assignments to the rename of the file
+	flushMu   sync.Mutex
+	dirty     bool
+	timer     *time.Timer
+}
+
+// NewFileAssignmentStore : Create an assignment store backed by the JSON file at path.
+// The assignments already present in the file are loaded; a missing file is created on the first assignment.
+func NewFileAssignmentStore(path string) (*FileAssignmentStore, error) {
+	store := &FileAssignmentStore{
+		InMemoryAssignmentStore: *NewInMemoryAssignmentStore(),
+		path:                    path,
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.assignments); err != nil {
+			return nil, errors.New("failed to parse the assignments file " + path + ": " + err.Error())
+		}
+	}
+	return store, nil
+}
+
+// Set : Store the assignment of the entity for the feature flag. The assignments are persisted in the background.
+func (s *FileAssignmentStore) Set(featureID, entityID string, assignment Assignment) error {
+	_ = s.InMemoryAssignmentStore.Set(featureID, entityID, assignment)
+	s.scheduleFlush()
+	return nil
+}
+
+// Reset : Remove the assignment of the entity, or of all the entities if entityID is empty, for the feature flag.
+// The assignments are persisted in the background.
+func (s *FileAssignmentStore) Reset(featureID, entityID string) error {
+	_ = s.InMemoryAssignmentStore.Reset(featureID, entityID)
+	s.scheduleFlush()
+	return nil
+}
+
+// scheduleFlush marks the assignments as changed, and schedules their writing unless it is already scheduled.
+func (s *FileAssignmentStore) scheduleFlush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.dirty = true
+	if s.timer == nil {
+		s.timer = time.AfterFunc(assignmentFlushDelay, func() {
+			if err := s.Flush(); err != nil {
+				log.Error(messages.AssignmentStoreFlushErr, err)
+			}
+		})
+	}
+}
+
+// Flush : Write the changed assignments to the file now. The assignments are written to a temporary file renamed
+// over the assignments file, so that a crash never leaves a truncated file behind.
+func (s *FileAssignmentStore) Flush() error {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+	s.flushMu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	dirty := s.dirty
+	s.dirty = false
+	s.flushMu.Unlock()
+	if !dirty {
+		return nil
+	}
+	s.mu.RLock()
+	data, err := json.Marshal(s.assignments)
+	s.mu.RUnlock()
+	if err == nil {
+		err = utils.WriteFileAtomic(s.path, data, 0644)
+	}
+	if err != nil {
+		// written again with the next change
+		s.flushMu.Lock()
+		s.dirty = true
+		s.flushMu.Unlock()
+	}
+	return err
+}
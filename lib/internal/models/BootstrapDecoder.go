@@ -0,0 +1,218 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BootstrapDecoder : converts a bootstrap file of some format into the JSON format of Config.
+type BootstrapDecoder func(data []byte) ([]byte, error)
+
+var (
+	bootstrapDecodersMu sync.RWMutex
+	bootstrapDecoders   = map[string]BootstrapDecoder{
+		"json": decodeJSONBootstrap,
+		"yaml": decodeYAMLBootstrap,
+	}
+	bootstrapExtensions = map[string]string{
+		".json": "json",
+		".yaml": "yaml",
+		".yml":  "yaml",
+	}
+)
+
+// RegisterBootstrapDecoder : Register the decoder of a bootstrap file format, e.g. "toml", and the file extensions
+// of the format, e.g. ".toml". A decoder registered for an existing format replaces it.
+func RegisterBootstrapDecoder(format string, decoder BootstrapDecoder, extensions ...string) {
+	bootstrapDecodersMu.Lock()
+	defer bootstrapDecodersMu.Unlock()
+	format = strings.ToLower(format)
+	bootstrapDecoders[format] = decoder
+	for _, extension := range extensions {
+		bootstrapExtensions[strings.ToLower(extension)] = format
+	}
+}
+
+// BootstrapFormat : Get the format of a bootstrap file, the explicit format if not empty, or the format of the file
+// extension. ok is false if no decoder is registered for the format.
+func BootstrapFormat(path, format string) (string, bool) {
+	bootstrapDecodersMu.RLock()
+	defer bootstrapDecodersMu.RUnlock()
+	if len(format) == 0 {
+		format = bootstrapExtensions[strings.ToLower(filepath.Ext(path))]
+	}
+	format = strings.ToLower(format)
+	_, ok := bootstrapDecoders[format]
+	return format, ok
+}
+
+// DecodeBootstrap : Convert a bootstrap file of the format into the JSON format of Config
+func DecodeBootstrap(data []byte, format string) ([]byte, error) {
+	bootstrapDecodersMu.RLock()
+	decoder, ok := bootstrapDecoders[strings.ToLower(format)]
+	bootstrapDecodersMu.RUnlock()
+	if !ok {
+		return nil, errors.New("unsupported bootstrap file format " + format)
+	}
+	return decoder(data)
+}
+
+func decodeJSONBootstrap(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// decodeYAMLBootstrap converts YAML into JSON. The errors are reported with the line of the YAML document, including
+// the values whose type does not match the Config structure.
+func decodeYAMLBootstrap(data []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return []byte(`{}`), nil
+	}
+	root := document.Content[0]
+	value, err := yamlValue(root)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var typeErr *json.UnmarshalTypeError
+	if err = json.Unmarshal(converted, &Config{}); errors.As(err, &typeErr) {
+		if node := findYAMLNode(root, strings.Split(typeErr.Field, "."), typeErr.Value); node != nil {
+			value := typeErr.Value
+			if node.Kind == yaml.ScalarNode {
+				value += fmt.Sprintf(" %q", node.Value)
+			}
+			return nil, fmt.Errorf("yaml: line %d: %s: cannot use %s as %s", node.Line, typeErr.Field, value, typeErr.Type.String())
+		}
+		return nil, fmt.Errorf("yaml: %s: cannot use %s as %s", typeErr.Field, typeErr.Value, typeErr.Type.String())
+	}
+	return converted, nil
+}
+
+// yamlValue converts a YAML node into the values of encoding/json.
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{}, len(node.Content)/2)
+		lines := make(map[string]int, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("yaml: line %d: mapping keys must be scalars", key.Line)
+			}
+			if key.Tag == "!!merge" {
+				return nil, fmt.Errorf("yaml: line %d: merge keys are not supported", key.Line)
+			}
+			if line, ok := lines[key.Value]; ok {
+				return nil, fmt.Errorf("yaml: line %d: mapping key %q already defined at line %d", key.Line, key.Value, line)
+			}
+			lines[key.Value] = key.Line
+			converted, err := yamlValue(value)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key.Value] = converted
+		}
+		return mapping, nil
+	case yaml.SequenceNode:
+		sequence := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			converted, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, converted)
+		}
+		return sequence, nil
+	}
+	var value interface{}
+	err := node.Decode(&value)
+	return value, err
+}
+
+// findYAMLNode returns the first node at the path of JSON field names whose value has the JSON type jsonType, e.g.
+// "string". The path holds the indices of the sequence items, or else every item of the sequences is searched.
+func findYAMLNode(node *yaml.Node, path []string, jsonType string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return findYAMLNode(node.Alias, path, jsonType)
+	}
+	if len(path) == 0 && yamlJSONType(node) == jsonType {
+		return node
+	}
+	if node.Kind == yaml.SequenceNode {
+		if len(path) > 0 {
+			if index, err := strconv.Atoi(path[0]); err == nil {
+				if index < 0 || index >= len(node.Content) {
+					return nil
+				}
+				return findYAMLNode(node.Content[index], path[1:], jsonType)
+			}
+		}
+		for _, item := range node.Content {
+			if found := findYAMLNode(item, path, jsonType); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if len(path) == 0 || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == path[0] {
+			return findYAMLNode(node.Content[i+1], path[1:], jsonType)
+		}
+	}
+	return nil
+}
+
+// yamlJSONType returns the type of the node, as named by json.UnmarshalTypeError.
+func yamlJSONType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		return "number"
+	case "!!bool":
+		return "bool"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
@@ -0,0 +1,210 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MergeActionAdded : the entry is not in the previous layers.
+const MergeActionAdded = "added"
+
+// MergeActionReplaced : the entry replaces the entry of the previous layers.
+const MergeActionReplaced = "replaced"
+
+// MergeActionOverridden : fields of the entry override those of the entry of the previous layers.
+const MergeActionOverridden = "overridden"
+
+// MergeLayer : the configurations of a bootstrap layer, in the JSON format of the bootstrap file.
+type MergeLayer struct {
+	Data []byte
+	// ReplaceEntries replaces the features, properties, segments and collections of the previous layers as a whole,
+	// instead of overriding their fields.
+	ReplaceEntries bool
+}
+
+// MergeChange : an entry added or changed by a bootstrap layer over the previous layers.
+type MergeChange struct {
+	Layer         int      `json:"layer"` // index of the layer
+	Kind          string   `json:"kind"`  // one of environment, collection, feature, property or segment
+	ID            string   `json:"id"`
+	EnvironmentID string   `json:"environment_id,omitempty"`
+	Action        string   `json:"action"`           // one of added, replaced or overridden
+	Fields        []string `json:"fields,omitempty"` // the overridden fields
+}
+
+// String : Human readable form of the change.
+func (mc MergeChange) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "layer %d: %s %s %s", mc.Layer, mc.Action, mc.Kind, mc.ID)
+	if len(mc.EnvironmentID) > 0 {
+		sb.WriteString(" (environment " + mc.EnvironmentID + ")")
+	}
+	if len(mc.Fields) > 0 {
+		sb.WriteString(": " + strings.Join(mc.Fields, ", "))
+	}
+	return sb.String()
+}
+
+// MergeReport : the changes of the bootstrap layers over the first layer, in the order of the layers.
+type MergeReport struct {
+	Layers  int           `json:"layers"`
+	Changes []MergeChange `json:"changes"`
+}
+
+// String : Human readable form of the report, one change per line.
+func (mr *MergeReport) String() string {
+	lines := make([]string, 0, len(mr.Changes))
+	for _, change := range mr.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// MergeBootstrap : Merge bootstrap layers, in order. The environments, collections and segments are merged by ID, and
+// the features and properties of an environment by ID. An entry of a later layer overrides the fields of the entry
+// of the previous layers, or replaces it with ReplaceEntries; the features and properties of an environment are
+// always merged.
+func MergeBootstrap(layers []MergeLayer) ([]byte, MergeReport, error) {
+	report := MergeReport{Layers: len(layers)}
+	merged := make(map[string]interface{})
+	for i, layer := range layers {
+		var config map[string]interface{}
+		if err := json.Unmarshal(layer.Data, &config); err != nil {
+			return nil, report, fmt.Errorf("layer %d: %w", i, err)
+		}
+		m := merger{layer: i, replace: layer.ReplaceEntries, report: &report}
+		for _, key := range sortedKeys(config) {
+			var err error
+			switch key {
+			case "environments":
+				merged[key], err = m.mergeEntries(merged[key], config[key], key, "environment", "environment_id", "")
+			case "collections":
+				merged[key], err = m.mergeEntries(merged[key], config[key], key, "collection", "collection_id", "")
+			case "segments":
+				merged[key], err = m.mergeEntries(merged[key], config[key], key, "segment", "segment_id", "")
+			default:
+				merged[key] = config[key]
+			}
+			if err != nil {
+				return nil, report, fmt.Errorf("layer %d: %w", i, err)
+			}
+		}
+	}
+	data, err := json.Marshal(merged)
+	return data, report, err
+}
+
+type merger struct {
+	layer   int
+	replace bool
+	report  *MergeReport
+}
+
+func (m merger) record(change MergeChange) {
+	// the first layer is the base of the others
+	if m.layer > 0 {
+		change.Layer = m.layer
+		m.report.Changes = append(m.report.Changes, change)
+	}
+}
+
+// mergeEntries merges the entries of a layer, identified by the idKey field, into the entries of the previous layers.
+func (m merger) mergeEntries(base, layer interface{}, key, kind, idKey, environmentID string) (interface{}, error) {
+	entries, ok := layer.([]interface{})
+	if !ok && layer != nil {
+		return nil, fmt.Errorf("%s is not an array", key)
+	}
+	merged, _ := base.([]interface{})
+	index := make(map[string]int, len(merged))
+	for i, entry := range merged {
+		if object, ok := entry.(map[string]interface{}); ok {
+			if id, ok := object[idKey].(string); ok {
+				index[id] = i
+			}
+		}
+	}
+	for _, entry := range entries {
+		object, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: entry is not an object", key)
+		}
+		id, ok := object[idKey].(string)
+		if !ok || len(id) == 0 {
+			return nil, fmt.Errorf("%s: entry without %s", key, idKey)
+		}
+		change := MergeChange{Kind: kind, ID: id, EnvironmentID: environmentID}
+		i, exists := index[id]
+		switch {
+		case !exists:
+			index[id] = len(merged)
+			merged = append(merged, object)
+			change.Action = MergeActionAdded
+			m.record(change)
+		case m.replace && kind != "environment":
+			merged[i] = object
+			change.Action = MergeActionReplaced
+			m.record(change)
+		default:
+			fields, err := m.overrideFields(merged[i].(map[string]interface{}), object, kind, id)
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) > 0 {
+				change.Action, change.Fields = MergeActionOverridden, fields
+				m.record(change)
+			}
+		}
+	}
+	return merged, nil
+}
+
+// overrideFields overrides the fields of an entry, and returns the fields changed. The features and properties of
+// an environment are merged instead.
+func (m merger) overrideFields(base, layer map[string]interface{}, kind, id string) ([]string, error) {
+	var fields []string
+	for _, field := range sortedKeys(layer) {
+		value := layer[field]
+		var err error
+		switch {
+		case kind == "environment" && field == "features":
+			base[field], err = m.mergeEntries(base[field], value, field, "feature", "feature_id", id)
+		case kind == "environment" && field == "properties":
+			base[field], err = m.mergeEntries(base[field], value, field, "property", "property_id", id)
+		case !reflect.DeepEqual(base[field], value):
+			base[field] = value
+			fields = append(fields, field)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -17,15 +17,30 @@
 package models
 
 import (
+	"time"
+
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 )
 
+// Sources of the configurations of the cache
+const (
+	DataSourceService         = "SERVICE"
+	DataSourcePersistentCache = "PERSISTENT_CACHE"
+	DataSourceBootstrap       = "BOOTSTRAP"
+	DataSourceSnapshot        = "SNAPSHOT"
+)
+
 // Cache : Cache struct
 type Cache struct {
 	FeatureMap       map[string]Feature
 	PropertyMap      map[string]Property
 	SegmentMap       map[string]Segment
 	SecretManagerMap map[string]interface{}
+	// Source is where the configurations come from, one of the DataSource constants, empty if unknown.
+	Source string
+	// FetchedAt is when the configurations were fetched from the service, or when the bootstrap file was written.
+	// It is zero if unknown, e.g. for a persistent cache written by an older version of the SDK.
+	FetchedAt time.Time
 }
 
 // CacheInstance : Cache Instance
@@ -33,14 +48,30 @@ var CacheInstance *Cache
 
 // SetCache : Set Cache
 func SetCache(featureMap map[string]Feature, propertyMap map[string]Property, segmentMap map[string]Segment) {
-	CacheInstance = new(Cache)
-	CacheInstance.FeatureMap = featureMap
-	CacheInstance.PropertyMap = propertyMap
-	CacheInstance.SegmentMap = segmentMap
-	CacheInstance.SecretManagerMap = make(map[string]interface{})
+	SetCacheWithOrigin(featureMap, propertyMap, segmentMap, "", time.Time{})
+}
+
+// SetCacheWithOrigin : Set Cache, recording where the configurations come from and when they were fetched
+func SetCacheWithOrigin(featureMap map[string]Feature, propertyMap map[string]Property, segmentMap map[string]Segment, source string, fetchedAt time.Time) {
+	cache := new(Cache)
+	cache.FeatureMap = featureMap
+	cache.PropertyMap = propertyMap
+	cache.SegmentMap = segmentMap
+	cache.SecretManagerMap = make(map[string]interface{})
+	cache.Source = source
+	cache.FetchedAt = fetchedAt
+	CacheInstance = cache
 	log.Debug(CacheInstance)
 }
 
+// Age : Get how long ago the configurations of the cache were fetched, at t. ok is false if unknown.
+func (c *Cache) Age(t time.Time) (age time.Duration, ok bool) {
+	if c == nil || c.FetchedAt.IsZero() {
+		return 0, false
+	}
+	return t.Sub(c.FetchedAt), true
+}
+
 // GetCacheInstance : Get Cache Instance
 func GetCacheInstance() *Cache {
 	return CacheInstance
@@ -0,0 +1,283 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AttributeProvider : implemented by entities that supply their own attributes for the rule evaluation.
+type AttributeProvider interface {
+	EntityAttributes() map[string]interface{}
+}
+
+// attributeTag is the struct tag used to name the entity attributes of a struct, e.g. `appconfig:"country"`.
+const attributeTag = "appconfig"
+
+// taggedField : an attribute-bearing field of a struct type.
+type taggedField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// taggedFieldsCache : reflect.Type -> []taggedField. Reflection is done once per struct type.
+var taggedFieldsCache sync.Map
+
+// attributeSet : the attributes of an entity, read from an attribute map or, for a tagged struct, from the struct
+// fields in place, so that no attribute map is built for each evaluation.
+type attributeSet struct {
+	values map[string]interface{}
+	entity reflect.Value
+	fields []taggedField
+}
+
+// mapAttributes returns the attribute set of an attribute map.
+func mapAttributes(values map[string]interface{}) attributeSet {
+	return attributeSet{values: values}
+}
+
+// lookup returns the named attribute. The zero value of an omitempty field is a missing attribute.
+func (a attributeSet) lookup(name string) (interface{}, bool) {
+	if a.fields == nil {
+		val, ok := a.values[name]
+		return val, ok
+	}
+	for _, field := range a.fields {
+		if field.name != name {
+			continue
+		}
+		fieldValue := a.entity.FieldByIndex(field.index)
+		if field.omitEmpty && fieldValue.IsZero() {
+			return nil, false
+		}
+		return fieldValue.Interface(), true
+	}
+	return nil, false
+}
+
+// isEmpty reports whether the entity has no attribute.
+func (a attributeSet) isEmpty() bool {
+	if a.fields == nil {
+		return len(a.values) == 0
+	}
+	for _, field := range a.fields {
+		if !field.omitEmpty || !a.entity.FieldByIndex(field.index).IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// entityAttributesOf returns the attributes of an entity, as consumed by the segment rules.
+//
+// The entity may be a map[string]interface{}, an AttributeProvider, or a struct (or pointer to a struct)
+// whose fields are tagged with `appconfig:"name"`. A tag of `appconfig:"name,omitempty"` skips the attribute
+// when the field holds its zero value, and `appconfig:"-"` ignores the field.
+func entityAttributesOf(entity interface{}) (attributeSet, error) {
+	switch e := entity.(type) {
+	case nil:
+		return attributeSet{}, nil
+	case map[string]interface{}:
+		return mapAttributes(e), nil
+	case AttributeProvider:
+		return mapAttributes(e.EntityAttributes()), nil
+	}
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return attributeSet{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return attributeSet{}, errors.New("unsupported entity type " + v.Type().String())
+	}
+	return attributeSet{entity: v, fields: getTaggedFields(v.Type())}, nil
+}
+
+func getTaggedFields(t reflect.Type) []taggedField {
+	if cached, ok := taggedFieldsCache.Load(t); ok {
+		return cached.([]taggedField)
+	}
+	fields := collectTaggedFields(t, nil)
+	taggedFieldsCache.Store(t, fields)
+	return fields
+}
+
+func collectTaggedFields(t reflect.Type, parentIndex []int) []taggedField {
+	var fields []taggedField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parentIndex...), i)
+		tag, tagged := field.Tag.Lookup(attributeTag)
+		// untagged embedded structs contribute their own tagged fields
+		if !tagged && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectTaggedFields(field.Type, index)...)
+			continue
+		}
+		if !tagged || tag == "-" || field.PkgPath != "" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		name := options[0]
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, taggedField{
+			name:      name,
+			index:     index,
+			omitEmpty: len(options) > 1 && options[1] == "omitempty",
+		})
+	}
+	return fields
+}
+
+// getAttributeValue looks up the attribute referenced by a rule in the entity attributes.
+//
+// An exact key match always wins, so flat attribute maps behave exactly as before.
+// Otherwise the attribute name is treated as a path and resolved through nested maps, slices, arrays and structs.
+// Two path notations are supported:
+//  1. dotted paths, e.g. "user.org.tier" or "devices.0.os"
+//  2. JSON pointers (RFC 6901), e.g. "/user/org/tier" or "/devices/0/os"
+func getAttributeValue(entityAttributes map[string]interface{}, attributeName string) (interface{}, bool) {
+	return resolveAttributeValue(mapAttributes(entityAttributes), attributeName, nil)
+}
+
+// resolveAttributeValue is getAttributeValue with a hook that is applied to every value read on the way to the attribute.
+// The hook receives the canonical JSON pointer of the value and may replace it, e.g. to invoke a lazy AttributeResolver.
+func resolveAttributeValue(entityAttributes attributeSet, attributeName string, resolve func(pointer string, value interface{}) (interface{}, bool)) (interface{}, bool) {
+	if resolve == nil {
+		resolve = func(_ string, value interface{}) (interface{}, bool) { return value, true }
+	}
+	if val, ok := entityAttributes.lookup(attributeName); ok {
+		return resolve(toJSONPointer([]string{attributeName}), val)
+	}
+	segments := splitAttributePath(attributeName)
+	if len(segments) < 2 && !strings.HasPrefix(attributeName, "/") {
+		return nil, false
+	}
+	var current interface{}
+	for i, segment := range segments {
+		var next interface{}
+		var ok bool
+		if i == 0 {
+			next, ok = entityAttributes.lookup(segment)
+		} else {
+			next, ok = lookupPathSegment(current, segment)
+		}
+		if !ok {
+			return nil, false
+		}
+		if current, ok = resolve(toJSONPointer(segments[:i+1]), next); !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// toJSONPointer builds the JSON pointer of the given path segments.
+func toJSONPointer(segments []string) string {
+	var sb strings.Builder
+	for _, segment := range segments {
+		sb.WriteString("/")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// splitAttributePath splits an attribute name into its path segments.
+func splitAttributePath(attributeName string) []string {
+	if strings.HasPrefix(attributeName, "/") {
+		segments := strings.Split(attributeName[1:], "/")
+		for i, segment := range segments {
+			// "~1" must be decoded before "~0", see RFC 6901 section 4.
+			segment = strings.ReplaceAll(segment, "~1", "/")
+			segments[i] = strings.ReplaceAll(segment, "~0", "~")
+		}
+		return segments
+	}
+	return strings.Split(attributeName, ".")
+}
+
+// lookupPathSegment resolves one path segment against the current value.
+func lookupPathSegment(current interface{}, segment string) (interface{}, bool) {
+	if current == nil {
+		return nil, false
+	}
+	// fast path for the most common shape of entity attributes
+	if m, ok := current.(map[string]interface{}); ok {
+		val, found := m[segment]
+		return val, found
+	}
+	v := reflect.ValueOf(current)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		val := v.MapIndex(reflect.ValueOf(segment).Convert(v.Type().Key()))
+		if !val.IsValid() {
+			return nil, false
+		}
+		return val.Interface(), true
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= v.Len() {
+			return nil, false
+		}
+		return v.Index(index).Interface(), true
+	case reflect.Struct:
+		return lookupStructField(v, segment)
+	}
+	return nil, false
+}
+
+// lookupStructField returns the exported struct field whose appconfig tag name, json tag name or Go field name
+// matches the segment.
+func lookupStructField(v reflect.Value, segment string) (interface{}, bool) {
+	for _, field := range getTaggedFields(v.Type()) {
+		if field.name == segment {
+			return v.FieldByIndex(field.index).Interface(), true
+		}
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == segment || (name == "" && field.Name == segment) {
+			return v.Field(i).Interface(), true
+		}
+	}
+	return nil, false
+}
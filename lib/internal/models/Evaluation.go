@@ -0,0 +1,170 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// AttributeResolver : lazily computes the value of an entity attribute.
+//
+// A resolver placed in the entity attributes (at the top level or nested) is invoked only when a segment rule
+// references that attribute, and at most once per evaluation. If the resolver returns an error the attribute is
+// treated as absent.
+type AttributeResolver func(ctx context.Context) (interface{}, error)
+
+// EvaluationOptions : optional settings of a single feature flag or property evaluation.
+type EvaluationOptions struct {
+	// Context is passed to the AttributeResolver functions of the entity attributes.
+	// Defaults to context.Background().
+	Context context.Context
+	// BucketingAttribute names the entity attribute whose value places the entity in a rollout bucket.
+	// It takes precedence over the bucketing attribute of the feature. Defaults to the entity ID.
+	BucketingAttribute string
+}
+
+// The reasons of an EvaluationDetails
+const (
+	// ReasonFeatureDisabled : the feature flag is disabled, the disabled value is served.
+	ReasonFeatureDisabled = "FEATURE_DISABLED"
+	// ReasonSegmentMatch : the entity matched a segment rule and is included in its rollout.
+	ReasonSegmentMatch = "SEGMENT_MATCH"
+	// ReasonSegmentRolloutExcluded : the entity matched a segment rule, but is excluded from its rollout.
+	ReasonSegmentRolloutExcluded = "SEGMENT_ROLLOUT_EXCLUDED"
+	// ReasonRolloutIncluded : the entity matched no segment rule and is included in the rollout of the feature flag.
+	ReasonRolloutIncluded = "ROLLOUT_INCLUDED"
+	// ReasonRolloutExcluded : the entity matched no segment rule and is excluded from the rollout of the feature flag.
+	ReasonRolloutExcluded = "ROLLOUT_EXCLUDED"
+	// ReasonStickyAssignment : the entity is served the value stored for it in the AssignmentStore.
+	ReasonStickyAssignment = "STICKY_ASSIGNMENT"
+	// ReasonScheduleInactive : the current time is outside the activation window of the feature flag, the disabled value is served.
+	ReasonScheduleInactive = "SCHEDULE_INACTIVE"
+	// ReasonPrerequisiteFailed : a prerequisite feature flag did not evaluate to its required value, see EvaluationDetails.FailedPrerequisite.
+	ReasonPrerequisiteFailed = "PREREQUISITE_FAILED"
+	// ReasonError : the evaluation failed, see EvaluationDetails.Error.
+	ReasonError = "ERROR"
+)
+
+// EvaluationDetails : the result of a feature flag evaluation and how it was reached.
+type EvaluationDetails struct {
+	FeatureID string      `json:"feature_id"`
+	EntityID  string      `json:"entity_id"`
+	Value     interface{} `json:"value"`
+	// Enabled is true when the entity is served the enabled value, a variant or a segment override value.
+	Enabled bool `json:"enabled"`
+	// SegmentID is the segment matched by the entity, empty if no segment rule matched.
+	SegmentID string `json:"segment_id,omitempty"`
+	// VariantKey is the key of the served variant of a multivariate feature flag.
+	VariantKey string `json:"variant_key,omitempty"`
+	// FailedPrerequisite is the feature ID of the prerequisite that was not met.
+	FailedPrerequisite string `json:"failed_prerequisite,omitempty"`
+	Reason             string `json:"reason"`
+	Error              string `json:"error,omitempty"`
+	// DataSource is where the evaluated configurations come from, and DataAge how long ago they were fetched.
+	// DataAge is 0 when unknown.
+	DataSource string        `json:"data_source,omitempty"`
+	DataAge    time.Duration `json:"data_age,omitempty"`
+}
+
+func evaluationError(featureID, entityID, message string) EvaluationDetails {
+	return EvaluationDetails{FeatureID: featureID, EntityID: entityID, Reason: ReasonError, Error: message}
+}
+
+// evaluationContext : state of one feature flag or property evaluation.
+type evaluationContext struct {
+	ctx        context.Context
+	entityID   string
+	attributes attributeSet
+	// bucketingAttribute is the per call bucketing attribute of the percentage rollouts.
+	bucketingAttribute string
+	// resolved memoizes the results of the attribute resolvers, keyed by attribute path.
+	resolved map[string]resolvedAttribute
+	// prerequisiteChain is the stack of feature flags whose prerequisites are being evaluated.
+	prerequisiteChain []string
+	// cache holds the segments and the prerequisite feature flags of the evaluation. Defaults to the global cache.
+	cache *Cache
+	// dryRun evaluates without side effects: no usage metering and no new sticky assignments.
+	dryRun bool
+	// ignoreAssignments evaluates the rules even for the entities that have a sticky assignment.
+	ignoreAssignments bool
+	// trace records the steps of the evaluation, see Feature.Explain.
+	trace *EvaluationTrace
+	// now is the time of the evaluation, against which the schedules are evaluated.
+	now time.Time
+}
+
+type resolvedAttribute struct {
+	value interface{}
+	ok    bool
+}
+
+func newEvaluationContext(entityID string, entityAttributes attributeSet, options EvaluationOptions) *evaluationContext {
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &evaluationContext{
+		ctx:                ctx,
+		entityID:           entityID,
+		attributes:         entityAttributes,
+		bucketingAttribute: options.BucketingAttribute,
+		now:                Now(),
+	}
+}
+
+// cacheInstance returns the cache of the evaluation.
+func (ec *evaluationContext) cacheInstance() *Cache {
+	if ec.cache != nil {
+		return ec.cache
+	}
+	return GetCacheInstance()
+}
+
+// attribute returns the value of the named entity attribute, invoking lazy resolvers on the way.
+func (ec *evaluationContext) attribute(attributeName string) (interface{}, bool) {
+	return resolveAttributeValue(ec.attributes, attributeName, ec.resolve)
+}
+
+// resolve replaces a lazy attribute with its resolved value. Values that are not resolvers are returned as is.
+func (ec *evaluationContext) resolve(path string, value interface{}) (interface{}, bool) {
+	var resolver AttributeResolver
+	switch r := value.(type) {
+	case AttributeResolver:
+		resolver = r
+	case func(context.Context) (interface{}, error):
+		resolver = r
+	default:
+		return value, true
+	}
+	if memo, ok := ec.resolved[path]; ok {
+		return memo.value, memo.ok
+	}
+	if ec.resolved == nil {
+		ec.resolved = make(map[string]resolvedAttribute)
+	}
+	resolvedValue, err := resolver(ec.ctx)
+	if err != nil {
+		log.Error("Failed to resolve the entity attribute ", path, " - ", err.Error())
+		ec.resolved[path] = resolvedAttribute{}
+		return nil, false
+	}
+	ec.resolved[path] = resolvedAttribute{value: resolvedValue, ok: true}
+	return resolvedValue, true
+}
@@ -0,0 +1,185 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// EvaluationTrace : the steps of a feature flag evaluation, see Feature.Explain.
+type EvaluationTrace struct {
+	FeatureID string `json:"feature_id"`
+	EntityID  string `json:"entity_id"`
+	// Notes are the steps that are neither segment rules nor rollouts, e.g. the evaluated prerequisites.
+	Notes []string `json:"notes,omitempty"`
+	// SegmentRules are the segment rules checked, in order. The evaluation stops at the first matching segment.
+	SegmentRules []SegmentRuleTrace `json:"segment_rules,omitempty"`
+	// Rollout is the feature level rollout, checked when no segment rule matched.
+	Rollout *RolloutTrace `json:"rollout,omitempty"`
+	// Result is the final decision.
+	Result EvaluationDetails `json:"result"`
+}
+
+// SegmentRuleTrace : a segment rule checked by the evaluation, with the segments checked.
+type SegmentRuleTrace struct {
+	Order             int                 `json:"order"`
+	Value             interface{}         `json:"value"`
+	RolloutPercentage interface{}         `json:"rollout_percentage"`
+	Segments          []SegmentMembership `json:"segments"`
+	// Rollout is the segment level rollout, checked when a segment of the rule matched.
+	Rollout *RolloutTrace `json:"rollout,omitempty"`
+}
+
+// RolloutTrace : the rollout bucket of the entity compared against a rollout percentage.
+type RolloutTrace struct {
+	// HashKey is the hashed key placing the entity in a bucket, "<bucketing key>:<feature id>[:<hash salt>]".
+	HashKey    string `json:"hash_key"`
+	Bucket     int    `json:"bucket"`
+	Percentage int    `json:"percentage"`
+	Included   bool   `json:"included"`
+}
+
+// Explain evaluates the feature flag like GetEvaluationDetails, and returns the steps of the evaluation.
+// The evaluation has no side effects: no usage metering, no new sticky assignment and no exposure event.
+func (f *Feature) Explain(entityID string, entityAttributes map[string]interface{}) EvaluationTrace {
+	trace := EvaluationTrace{FeatureID: f.GetFeatureID(), EntityID: entityID}
+	if len(entityID) <= 0 {
+		log.Error("Feature flag evaluation: ", messages.InvalidEntityId, "Explain")
+		trace.Result = evaluationError(f.GetFeatureID(), entityID, messages.InvalidEntityId+"Explain")
+		return trace
+	}
+	if !f.isFeatureValid() {
+		trace.Result = evaluationError(f.GetFeatureID(), entityID, "invalid feature flag, feature struct has empty values for required fields")
+		return trace
+	}
+	ec := newEvaluationContext(entityID, mapAttributes(entityAttributes), EvaluationOptions{})
+	ec.dryRun, ec.trace = true, &trace
+	details := f.featureEvaluation(ec)
+	details.Value = getTypeCastedValue(details.Value, f.GetFeatureDataType(), f.GetFeatureDataFormat())
+	trace.Result = details
+	return trace
+}
+
+func (t *EvaluationTrace) note(note string) {
+	if t != nil {
+		t.Notes = append(t.Notes, note)
+	}
+}
+
+func (t *EvaluationTrace) addSegmentRule(segmentRule SegmentRule) {
+	if t != nil {
+		t.SegmentRules = append(t.SegmentRules, SegmentRuleTrace{
+			Order:             segmentRule.GetOrder(),
+			Value:             segmentRule.GetValue(),
+			RolloutPercentage: segmentRule.GetRolloutPercentage(),
+		})
+	}
+}
+
+func (t *EvaluationTrace) addSegment(membership SegmentMembership) {
+	if t != nil && len(t.SegmentRules) > 0 {
+		last := &t.SegmentRules[len(t.SegmentRules)-1]
+		last.Segments = append(last.Segments, membership)
+	}
+}
+
+func (t *EvaluationTrace) addRollout(rollout *RolloutTrace, segmentLevel bool) {
+	if t == nil {
+		return
+	}
+	if segmentLevel && len(t.SegmentRules) > 0 {
+		t.SegmentRules[len(t.SegmentRules)-1].Rollout = rollout
+	} else {
+		t.Rollout = rollout
+	}
+}
+
+// JSON : Render the trace as indented JSON
+func (t EvaluationTrace) JSON() string {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// String : Render the trace as human-readable text
+func (t EvaluationTrace) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "feature %s, entity %s\n", t.FeatureID, t.EntityID)
+	for _, note := range t.Notes {
+		fmt.Fprintf(&sb, "  %s\n", note)
+	}
+	for _, segmentRule := range t.SegmentRules {
+		fmt.Fprintf(&sb, "  segment rule %d: value %s, rollout %s\n", segmentRule.Order, traceValue(segmentRule.Value), traceValue(segmentRule.RolloutPercentage))
+		for _, segment := range segmentRule.Segments {
+			fmt.Fprintf(&sb, "    segment %s (%s): %s\n", segment.SegmentID, segment.Name, matchText(segment.Matched, "matched", "not matched"))
+			if len(segment.Name) == 0 && len(segment.Rules) == 0 {
+				sb.WriteString("      the segment does not exist\n")
+			}
+			for _, rule := range segment.Rules {
+				fmt.Fprintf(&sb, "      [%s] %s %s %s: %s\n", matchText(rule.Passed, "pass", "fail"), rule.AttributeName, rule.Operator, traceValue(rule.Values), rule.Reason)
+			}
+		}
+		if segmentRule.Rollout != nil {
+			sb.WriteString("    " + segmentRule.Rollout.String() + "\n")
+		}
+	}
+	if t.Rollout != nil {
+		sb.WriteString("  " + t.Rollout.String() + "\n")
+	}
+	fmt.Fprintf(&sb, "  result: %s (%s)", traceValue(t.Result.Value), t.Result.Reason)
+	if len(t.Result.SegmentID) > 0 {
+		fmt.Fprintf(&sb, ", segment %s", t.Result.SegmentID)
+	}
+	if len(t.Result.VariantKey) > 0 {
+		fmt.Fprintf(&sb, ", variant %s", t.Result.VariantKey)
+	}
+	if len(t.Result.FailedPrerequisite) > 0 {
+		fmt.Fprintf(&sb, ", failed prerequisite %s", t.Result.FailedPrerequisite)
+	}
+	if len(t.Result.Error) > 0 {
+		fmt.Fprintf(&sb, ", error: %s", t.Result.Error)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// String : Render the rollout decision as human-readable text
+func (r RolloutTrace) String() string {
+	return fmt.Sprintf("rollout: bucket %d of %q vs %d%%: %s", r.Bucket, r.HashKey, r.Percentage, matchText(r.Included, "included", "excluded"))
+}
+
+func traceValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func matchText(ok bool, yes, no string) string {
+	if ok {
+		return yes
+	}
+	return no
+}
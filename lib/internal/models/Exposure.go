@@ -0,0 +1,267 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// ExposureEvent : an entity was served a value of a feature flag.
+type ExposureEvent struct {
+	FeatureID  string      `json:"feature_id"`
+	EntityID   string      `json:"entity_id"`
+	Value      interface{} `json:"value"`
+	Enabled    bool        `json:"enabled"`
+	VariantKey string      `json:"variant_key,omitempty"`
+	SegmentID  string      `json:"segment_id,omitempty"`
+	Reason     string      `json:"reason"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// ExposureSink : receives the exposure events of the feature flag evaluations.
+//
+// Emit is called synchronously by the evaluation, so implementations should return quickly.
+// An error returned by Emit does not affect the evaluated value. The errors are counted, and logged at most once per
+// minute with their count.
+type ExposureSink interface {
+	Emit(event ExposureEvent) error
+}
+
+// exposureEmitter forwards the exposure events to the sink, skipping the repeated exposures of an entity to the
+// same value of a feature flag within the deduplication window.
+type exposureEmitter struct {
+	sink      ExposureSink
+	window    time.Duration
+	mu        sync.Mutex
+	seen      map[exposureKey]exposureRecord
+	lastSweep time.Time
+	errors    int64     // the errors of the sink since the last log
+	lastLog   time.Time // when the errors were last logged
+}
+
+// exposureErrorLogInterval is the minimum interval between the logs of the errors of the sink.
+const exposureErrorLogInterval = time.Minute
+
+type exposureKey struct {
+	featureID string
+	entityID  string
+}
+
+type exposureRecord struct {
+	served string
+	at     time.Time
+}
+
+var exposures *exposureEmitter
+var exposuresMu sync.RWMutex
+
+// SetExposureSink : Set the sink of the exposure events. Repeated exposures of an entity to the same value of a
+// feature flag are emitted once per window; a window of 0 emits every evaluation. A nil sink disables the events.
+func SetExposureSink(sink ExposureSink, window time.Duration) {
+	exposuresMu.Lock()
+	defer exposuresMu.Unlock()
+	if sink == nil {
+		exposures = nil
+		return
+	}
+	exposures = &exposureEmitter{sink: sink, window: window, seen: make(map[exposureKey]exposureRecord)}
+}
+
+// emitExposure reports the evaluation to the exposure sink, if one is set. Failed evaluations are not reported.
+func emitExposure(details EvaluationDetails) {
+	exposuresMu.RLock()
+	emitter := exposures
+	exposuresMu.RUnlock()
+	if emitter == nil || details.Reason == ReasonError {
+		return
+	}
+	event := ExposureEvent{
+		FeatureID:  details.FeatureID,
+		EntityID:   details.EntityID,
+		Value:      details.Value,
+		Enabled:    details.Enabled,
+		VariantKey: details.VariantKey,
+		SegmentID:  details.SegmentID,
+		Reason:     details.Reason,
+		Timestamp:  Now().UTC(),
+	}
+	if emitter.isDuplicate(event) {
+		return
+	}
+	if err := emitter.sink.Emit(event); err != nil {
+		emitter.failed(err)
+	}
+}
+
+// failed counts an error of the sink, and logs the errors counted at most once per exposureErrorLogInterval, so that
+// a slow or failing sink does not flood the log from the evaluations.
+func (e *exposureEmitter) failed(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors++
+	if time.Since(e.lastLog) < exposureErrorLogInterval {
+		return
+	}
+	log.Error(messages.ExposureSinkError, err.Error(), fmt.Sprintf(" (%d failed exposure events)", e.errors))
+	e.errors, e.lastLog = 0, time.Now()
+}
+
+func (e *exposureEmitter) isDuplicate(event ExposureEvent) bool {
+	if e.window <= 0 {
+		return false
+	}
+	key := exposureKey{featureID: event.FeatureID, entityID: event.EntityID}
+	served := fmt.Sprint(event.VariantKey, ":", event.Value)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if record, ok := e.seen[key]; ok && record.served == served && event.Timestamp.Sub(record.at) < e.window {
+		return true
+	}
+	e.seen[key] = exposureRecord{served: served, at: event.Timestamp}
+	// forget the expired exposures once per window, so that the map does not grow with every entity ever seen
+	if event.Timestamp.Sub(e.lastSweep) >= e.window {
+		for k, record := range e.seen {
+			if event.Timestamp.Sub(record.at) >= e.window {
+				delete(e.seen, k)
+			}
+		}
+		e.lastSweep = event.Timestamp
+	}
+	return false
+}
+
+// JSONLExposureSink : an ExposureSink that appends the events to a file, one JSON object per line.
+// The events are buffered and written in the background, off the evaluations; they are dropped when the buffer is full.
+type JSONLExposureSink struct {
+	mu      sync.RWMutex
+	closed  bool
+	events  chan ExposureEvent
+	done    chan struct{}
+	file    *os.File
+	err     error // the first write error
+	dropped atomic.Int64
+}
+
+// jsonlExposureBuffer is the number of events buffered by a JSONLExposureSink.
+const jsonlExposureBuffer = 1024
+
+// NewJSONLExposureSink : Create a sink appending the exposure events to the file at path. The file is created if needed.
+func NewJSONLExposureSink(path string) (*JSONLExposureSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &JSONLExposureSink{events: make(chan ExposureEvent, jsonlExposureBuffer), done: make(chan struct{}), file: file}
+	go s.write()
+	return s, nil
+}
+
+// write appends the events to the file, flushing them whenever no more events are waiting.
+func (s *JSONLExposureSink) write() {
+	defer close(s.done)
+	writer := bufio.NewWriter(s.file)
+	encoder := json.NewEncoder(writer)
+	for event := range s.events {
+		err := encoder.Encode(event)
+		if err == nil && len(s.events) == 0 {
+			err = writer.Flush()
+		}
+		if err != nil && s.err == nil {
+			s.err = err
+			log.Error(messages.ExposureSinkError, err.Error())
+		}
+	}
+	if err := writer.Flush(); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Emit : Queue the event to be appended to the file, without blocking
+func (s *JSONLExposureSink) Emit(event ExposureEvent) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errors.New("the exposure file is closed, dropping the exposure of " + event.FeatureID + " to " + event.EntityID)
+	}
+	select {
+	case s.events <- event:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return errors.New("the exposure file buffer is full, dropping the exposure of " + event.FeatureID + " to " + event.EntityID)
+	}
+}
+
+// Dropped : Get the number of events dropped because the buffer was full
+func (s *JSONLExposureSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close : Write the buffered events, and close the file. It returns the first write error, if any.
+func (s *JSONLExposureSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.events)
+	s.mu.Unlock()
+	<-s.done
+	if err := s.file.Close(); s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
+
+// ChannelExposureSink : an ExposureSink that sends the events to a channel.
+// The events are dropped, and counted, when the channel is full.
+type ChannelExposureSink struct {
+	events  chan<- ExposureEvent
+	dropped atomic.Int64
+}
+
+// NewChannelExposureSink : Create a sink sending the exposure events to the channel
+func NewChannelExposureSink(events chan<- ExposureEvent) *ChannelExposureSink {
+	return &ChannelExposureSink{events: events}
+}
+
+// Emit : Send the event to the channel, without blocking
+func (s *ChannelExposureSink) Emit(event ExposureEvent) error {
+	select {
+	case s.events <- event:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return errors.New("the exposure channel is full, dropping the exposure of " + event.FeatureID + " to " + event.EntityID)
+	}
+}
+
+// Dropped : Get the number of events dropped because the channel was full
+func (s *ChannelExposureSink) Dropped() int64 {
+	return s.dropped.Load()
+}
@@ -21,7 +21,11 @@ import (
 	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
 
+	"fmt"
+	"reflect"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 )
@@ -37,6 +41,41 @@ type Feature struct {
 	SegmentRules      []SegmentRule `json:"segment_rules"`
 	Enabled           bool          `json:"enabled"`
 	RolloutPercentage *int          `json:"rollout_percentage"`
+	// BucketingAttribute names the entity attribute whose value places the entity in a rollout bucket,
+	// so that e.g. every entity of a tenant gets the same value. Defaults to the entity ID.
+	BucketingAttribute string `json:"bucketing_attribute,omitempty"`
+	// Variants split the entities that are served the enabled value across several values by weight.
+	Variants []Variant `json:"variants,omitempty"`
+	// Prerequisites are feature flags that must evaluate to the required values, for the same entity,
+	// before this feature flag serves anything but its disabled value.
+	Prerequisites []Prerequisite `json:"prerequisites,omitempty"`
+	// HashSalt is appended to the hashed keys of the percentage rollouts and of the variants. Changing it reshuffles
+	// the entities, and distinct salts decorrelate the cohorts of features rolled out together. Empty by default,
+	// which keeps the buckets shared with the other App Configuration SDKs.
+	HashSalt string `json:"hash_salt,omitempty"`
+	// Schedule restricts the feature flag to an activation window, and ramps its rollout percentage up over time.
+	Schedule *Schedule `json:"schedule,omitempty"`
+}
+
+// Prerequisite : a feature flag, and the value it must evaluate to, that another feature flag depends on
+type Prerequisite struct {
+	FeatureID string      `json:"feature_id"`
+	Value     interface{} `json:"value"`
+}
+
+// Variant : a weighted value of a multivariate feature flag
+type Variant struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Weight int         `json:"weight"`
+}
+
+// GetValue : Get the value of the variant, type casted as per the data type and format of its feature flag
+func (v *Variant) GetValue(dataType, format string) interface{} {
+	if format == "YAML" {
+		return getTypeCastedValue(v.Value, dataType, format)
+	}
+	return v.Value
 }
 
 // GetFeatureName : Get Feature Name
@@ -89,6 +128,31 @@ func (f *Feature) GetRolloutPercentage() int {
 	return *f.RolloutPercentage
 }
 
+// GetBucketingAttribute : Get the entity attribute used to bucket the entities of the percentage rollouts
+func (f *Feature) GetBucketingAttribute() string {
+	return f.BucketingAttribute
+}
+
+// GetVariants : Get the variants of a multivariate feature flag
+func (f *Feature) GetVariants() []Variant {
+	return f.Variants
+}
+
+// GetSchedule : Get the activation window and the rollout ramp of the feature flag, nil if it has none
+func (f *Feature) GetSchedule() *Schedule {
+	return f.Schedule
+}
+
+// GetHashSalt : Get the salt of the rollout and variant hashing
+func (f *Feature) GetHashSalt() string {
+	return f.HashSalt
+}
+
+// GetPrerequisites : Get the prerequisite feature flags
+func (f *Feature) GetPrerequisites() []Prerequisite {
+	return f.Prerequisites
+}
+
 // GetSegmentRules : Get Segment Rules
 func (f *Feature) GetSegmentRules() []SegmentRule {
 	return f.SegmentRules
@@ -114,11 +178,21 @@ func (f *Feature) IsEnabled() bool {
 // An attribute is a parameter that is used to define a segment. The SDK uses the attribute values to determine if the
 // specified entity satisfies the targeting rules, and returns the appropriate feature flag value.
 func (f *Feature) GetCurrentValue(entityID string, entityAttributes ...map[string]interface{}) interface{} {
-	log.Debug(messages.RetrievingFeature)
-	if len(entityID) <= 0 {
-		log.Error("Feature flag evaluation: ", messages.InvalidEntityId, "GetCurrentValue")
-		return nil
-	}
+	return f.GetCurrentValueWithOptions(entityID, EvaluationOptions{}, entityAttributes...)
+}
+
+// GetCurrentValueWithOptions returns one of the Enabled/Disabled/Overridden value based on the evaluation.
+//
+// It behaves like GetCurrentValue, with the evaluation tuned by options.
+// The entityAttributes may contain AttributeResolver functions, which are invoked with options.Context
+// only when a segment rule references that attribute.
+func (f *Feature) GetCurrentValueWithOptions(entityID string, options EvaluationOptions, entityAttributes ...map[string]interface{}) interface{} {
+	return f.GetEvaluationDetails(entityID, options, entityAttributes...).Value
+}
+
+// GetEvaluationDetails evaluates the feature flag like GetCurrentValueWithOptions, and returns the evaluated value
+// along with how it was reached: the matched segment, the served variant of a multivariate feature flag, and the reason.
+func (f *Feature) GetEvaluationDetails(entityID string, options EvaluationOptions, entityAttributes ...map[string]interface{}) EvaluationDetails {
 	var temp map[string]interface{}
 	switch len(entityAttributes) {
 	case 0: // Do Nothing
@@ -126,31 +200,100 @@ func (f *Feature) GetCurrentValue(entityID string, entityAttributes ...map[strin
 		temp = entityAttributes[0]
 	default:
 		log.Error("Feature flag evaluation: ", messages.IncorrectUsageOfEntityAttributes, "GetCurrentValue")
-		return nil
+		return evaluationError(f.GetFeatureID(), entityID, messages.IncorrectUsageOfEntityAttributes+"GetCurrentValue")
+	}
+	return f.evaluationDetails(entityID, mapAttributes(temp), options)
+}
+
+// evaluationDetails evaluates the feature flag for the entity attributes, see GetEvaluationDetails.
+func (f *Feature) evaluationDetails(entityID string, entityAttributes attributeSet, options EvaluationOptions) EvaluationDetails {
+	log.Debug(messages.RetrievingFeature)
+	if len(entityID) <= 0 {
+		log.Error("Feature flag evaluation: ", messages.InvalidEntityId, "GetCurrentValue")
+		return evaluationError(f.GetFeatureID(), entityID, messages.InvalidEntityId+"GetCurrentValue")
 	}
 	if f.isFeatureValid() {
-		val, _ := f.featureEvaluation(entityID, temp)
-		return getTypeCastedValue(val, f.GetFeatureDataType(), f.GetFeatureDataFormat())
+		details := f.featureEvaluation(newEvaluationContext(entityID, entityAttributes, options))
+		details.Value = getTypeCastedValue(details.Value, f.GetFeatureDataType(), f.GetFeatureDataFormat())
+		if cache := GetCacheInstance(); cache != nil {
+			details.DataSource = cache.Source
+			details.DataAge, _ = cache.Age(Now())
+		}
+		emitExposure(details)
+		return details
 	}
 	log.Error("Invalid feature flag. Feature struct has empty values for required fields.")
-	return nil
+	return evaluationError(f.GetFeatureID(), entityID, "invalid feature flag, feature struct has empty values for required fields")
+}
+
+// GetCurrentValueFor returns one of the Enabled/Disabled/Overridden value based on the evaluation.
+//
+// It behaves like GetCurrentValue, but the entity attributes are read from entity, which can be any of
+//  1. a map of type `map[string]interface{}`
+//  2. a value implementing the AttributeProvider interface
+//  3. a struct, or a pointer to a struct, whose fields are tagged with `appconfig:"attributeName"`
+//
+// Reflection metadata of a struct type is computed once and reused for later evaluations.
+func (f *Feature) GetCurrentValueFor(entityID string, entity interface{}) interface{} {
+	entityAttributes, err := entityAttributesOf(entity)
+	if err != nil {
+		log.Error("Feature flag evaluation: ", messages.InvalidEntity, "GetCurrentValueFor. ", err.Error())
+		return nil
+	}
+	return f.evaluationDetails(entityID, entityAttributes, EvaluationOptions{}).Value
 }
 
 func (f *Feature) isFeatureValid() bool {
 	return !(f.Name == "" || f.FeatureID == "" || f.DataType == "" || f.EnabledValue == nil || f.DisabledValue == nil)
 }
-func (f *Feature) featureEvaluation(entityID string, entityAttributes map[string]interface{}) (interface{}, bool) {
+func (f *Feature) featureEvaluation(ec *evaluationContext) (details EvaluationDetails) {
 
+	entityID := ec.entityID
+	details = EvaluationDetails{FeatureID: f.GetFeatureID(), EntityID: entityID}
 	var evaluatedSegmentID string = constants.DefaultSegmentID
 	defer func() {
-		utils.GetMeteringInstance().RecordEvaluation(f.GetFeatureID(), "", entityID, evaluatedSegmentID)
+		if ec.dryRun {
+			return
+		}
+		if len(details.VariantKey) > 0 {
+			utils.GetMeteringInstance().RecordVariantEvaluation(f.GetFeatureID(), entityID, evaluatedSegmentID, details.VariantKey)
+		} else {
+			utils.GetMeteringInstance().RecordEvaluation(f.GetFeatureID(), "", entityID, evaluatedSegmentID)
+		}
 	}()
 
 	if f.Enabled {
 		log.Debug(messages.EvaluatingFeature)
 		defer utils.GracefullyHandleError()
 
-		if len(f.GetSegmentRules()) > 0 && len(entityAttributes) > 0 {
+		if !f.Schedule.isActive(ec.now) {
+			details.Value, details.Reason = f.GetDisabledValue(), ReasonScheduleInactive
+			return details
+		}
+
+		if failed := f.failedPrerequisite(ec); len(failed) > 0 {
+			details.Value, details.Reason, details.FailedPrerequisite = f.GetDisabledValue(), ReasonPrerequisiteFailed, failed
+			return details
+		}
+
+		if store := GetAssignmentStore(); store != nil && !ec.ignoreAssignments {
+			if assignment, ok := f.getAssignment(store, entityID); ok {
+				details.Value, details.Enabled, details.Reason = assignment.Value, true, ReasonStickyAssignment
+				details.SegmentID, details.VariantKey = assignment.SegmentID, assignment.VariantKey
+				if len(assignment.SegmentID) > 0 {
+					evaluatedSegmentID = assignment.SegmentID
+				}
+				return details
+			}
+			if !ec.dryRun {
+				defer f.setAssignment(store, &details)
+			}
+		}
+
+		if len(f.GetSegmentRules()) > 0 && ec.attributes.isEmpty() {
+			ec.trace.note("the segment rules are skipped, the entity has no attributes")
+		}
+		if len(f.GetSegmentRules()) > 0 && !ec.attributes.isEmpty() {
 			var rulesMap map[int]SegmentRule
 			rulesMap = f.parseRules(f.GetSegmentRules())
 
@@ -164,36 +307,200 @@ func (f *Feature) featureEvaluation(entityID string, entityAttributes map[string
 			// after sorting , pick up each map element as per keys order
 			for _, k := range keys {
 				segmentRule := rulesMap[k]
+				ec.trace.addSegmentRule(segmentRule)
 				for _, rule := range segmentRule.GetRules() {
 					for _, segmentKey := range rule.Segments {
-						if f.evaluateSegment(string(segmentKey), entityAttributes) {
+						if f.evaluateSegment(string(segmentKey), ec) {
 							evaluatedSegmentID = segmentKey
+							details.SegmentID = segmentKey
 							var segmentLevelRolloutPercentage int
 							if segmentRule.GetRolloutPercentage() == "$default" {
-								segmentLevelRolloutPercentage = f.GetRolloutPercentage()
+								segmentLevelRolloutPercentage = f.rolloutPercentageAt(ec.now)
 							} else {
 								segmentLevelRolloutPercentage = int(segmentRule.GetRolloutPercentage().(float64))
 							}
-							if segmentLevelRolloutPercentage == 100 || GetNormalizedValue(entityID+":"+f.GetFeatureID()) < segmentLevelRolloutPercentage {
+							if f.inRollout(ec, segmentLevelRolloutPercentage, true) {
+								details.Reason = ReasonSegmentMatch
 								if segmentRule.GetValue() == "$default" {
-									return f.GetEnabledValue(), true
+									f.serveEnabledValue(ec, &details)
 								} else {
-									return segmentRule.GetValue(), true
+									details.Value, details.Enabled = segmentRule.GetValue(), true
 								}
 							} else {
-								return f.GetDisabledValue(), false
+								details.Value, details.Reason = f.GetDisabledValue(), ReasonSegmentRolloutExcluded
 							}
+							return details
 						}
 					}
 				}
 			}
 		}
-		if f.GetRolloutPercentage() == 100 || GetNormalizedValue(entityID+":"+f.GetFeatureID()) < f.GetRolloutPercentage() {
-			return f.GetEnabledValue(), true
+		if f.inRollout(ec, f.rolloutPercentageAt(ec.now), false) {
+			details.Reason = ReasonRolloutIncluded
+			f.serveEnabledValue(ec, &details)
+			return details
+		}
+		details.Value, details.Reason = f.GetDisabledValue(), ReasonRolloutExcluded
+		return details
+	}
+	details.Value, details.Reason = f.GetDisabledValue(), ReasonFeatureDisabled
+	return details
+}
+
+// failedPrerequisite evaluates the prerequisites for the entity, in order, and returns the feature ID of the first
+// one that does not evaluate to its required value. It returns an empty string when all the prerequisites are met.
+func (f *Feature) failedPrerequisite(ec *evaluationContext) string {
+	if len(f.Prerequisites) == 0 {
+		return ""
+	}
+	ec.prerequisiteChain = append(ec.prerequisiteChain, f.GetFeatureID())
+	defer func() { ec.prerequisiteChain = ec.prerequisiteChain[:len(ec.prerequisiteChain)-1] }()
+	for _, prerequisite := range f.Prerequisites {
+		if !prerequisite.isMet(ec) {
+			return prerequisite.FeatureID
+		}
+	}
+	return ""
+}
+
+func (p Prerequisite) isMet(ec *evaluationContext) bool {
+	for _, featureID := range ec.prerequisiteChain {
+		if featureID == p.FeatureID {
+			log.Error(messages.PrerequisiteCycle, strings.Join(append(ec.prerequisiteChain, p.FeatureID), " -> "))
+			return false
+		}
+	}
+	feature, ok := ec.cacheInstance().FeatureMap[p.FeatureID]
+	if !ok || !feature.isFeatureValid() {
+		log.Error(messages.PrerequisiteNotFound, p.FeatureID)
+		return false
+	}
+	// the prerequisite is evaluated without side effects: only the evaluation of the requested feature flag is metered
+	// and assigned to the entity
+	trace, dryRun := ec.trace, ec.dryRun
+	ec.trace, ec.dryRun = nil, true
+	value := getTypeCastedValue(feature.featureEvaluation(ec).Value, feature.GetFeatureDataType(), feature.GetFeatureDataFormat())
+	ec.trace, ec.dryRun = trace, dryRun
+	trace.note(fmt.Sprintf("prerequisite %s evaluated to %v, %v is required", p.FeatureID, value, p.Value))
+	return reflect.DeepEqual(value, getTypeCastedValue(p.Value, feature.GetFeatureDataType(), feature.GetFeatureDataFormat()))
+}
+
+// getAssignment returns the sticky assignment of the entity. A store error is logged and treated as no assignment.
+func (f *Feature) getAssignment(store AssignmentStore, entityID string) (Assignment, bool) {
+	assignment, ok, err := store.Get(f.GetFeatureID(), entityID)
+	if err != nil {
+		log.Error(messages.AssignmentStoreError, err.Error())
+		return Assignment{}, false
+	}
+	return assignment, ok
+}
+
+// setAssignment stores the evaluated value of the entity, if it is served the enabled value, a variant or a segment override value.
+func (f *Feature) setAssignment(store AssignmentStore, details *EvaluationDetails) {
+	if !details.Enabled {
+		return
+	}
+	assignment := Assignment{
+		Value:      details.Value,
+		SegmentID:  details.SegmentID,
+		VariantKey: details.VariantKey,
+		AssignedAt: Now().UTC(),
+	}
+	if err := store.Set(f.GetFeatureID(), details.EntityID, assignment); err != nil {
+		log.Error(messages.AssignmentStoreError, err.Error())
+	}
+}
+
+// serveEnabledValue sets the enabled value in the details. For a multivariate feature flag, the enabled value is
+// the variant picked by the weighted distribution.
+func (f *Feature) serveEnabledValue(ec *evaluationContext, details *EvaluationDetails) {
+	details.Enabled = true
+	if variant := f.pickVariant(ec); variant != nil {
+		details.Value = variant.GetValue(f.GetFeatureDataType(), f.GetFeatureDataFormat())
+		details.VariantKey = variant.Key
+		return
+	}
+	details.Value = f.GetEnabledValue()
+}
+
+// pickVariant returns the variant of the entity, or nil if the feature flag has no variants with a positive weight.
+// The murmur3 hash of "<hash key>:variants" places the entity in the weighted distribution.
+// It is independent of the rollout bucket, so that the entities included by a rollout are split across all variants.
+func (f *Feature) pickVariant(ec *evaluationContext) *Variant {
+	totalWeight := 0
+	for _, variant := range f.Variants {
+		if variant.Weight > 0 {
+			totalWeight += variant.Weight
+		}
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+	point := getNormalizedFraction(f.hashKey(ec)+":variants") * float64(totalWeight)
+	cumulativeWeight := 0
+	for i := range f.Variants {
+		if f.Variants[i].Weight <= 0 {
+			continue
+		}
+		cumulativeWeight += f.Variants[i].Weight
+		if point < float64(cumulativeWeight) {
+			return &f.Variants[i]
+		}
+	}
+	return nil
+}
+
+// rolloutPercentageAt returns the rollout percentage of the feature flag at t, which follows the ramp of its schedule, if any.
+func (f *Feature) rolloutPercentageAt(t time.Time) int {
+	if f.Schedule != nil && f.Schedule.Ramp != nil {
+		return f.Schedule.Ramp.percentageAt(t)
+	}
+	return f.GetRolloutPercentage()
+}
+
+// inRollout tells whether the entity is included in a rollout of the given percentage, and traces the decision.
+func (f *Feature) inRollout(ec *evaluationContext, percentage int, segmentLevel bool) bool {
+	if ec.trace == nil {
+		return percentage == 100 || f.rolloutBucket(ec) < percentage
+	}
+	rollout := &RolloutTrace{HashKey: f.hashKey(ec), Bucket: f.rolloutBucket(ec), Percentage: percentage}
+	rollout.Included = percentage == 100 || rollout.Bucket < percentage
+	ec.trace.addRollout(rollout, segmentLevel)
+	return rollout.Included
+}
+
+// rolloutBucket returns the bucket (0 to 99) of the entity, that is compared against the rollout percentages.
+// Without a hash salt or seed, the murmur3 normalization of "<bucketing key>:<feature id>" is shared with the other
+// App Configuration SDKs.
+func (f *Feature) rolloutBucket(ec *evaluationContext) int {
+	return GetNormalizedValue(f.hashKey(ec))
+}
+
+// hashKey returns "<bucketing key>:<feature id>", followed by ":<hash salt>" when the feature has a salt.
+func (f *Feature) hashKey(ec *evaluationContext) string {
+	key := f.bucketingKey(ec) + ":" + f.GetFeatureID()
+	if len(f.HashSalt) > 0 {
+		key += ":" + f.HashSalt
+	}
+	return key
+}
+
+// bucketingKey returns the value of the bucketing attribute (from the evaluation options, else from the feature),
+// falling back to the entity ID when no bucketing attribute is set or the entity does not have it.
+func (f *Feature) bucketingKey(ec *evaluationContext) string {
+	attributeName := ec.bucketingAttribute
+	if len(attributeName) == 0 {
+		attributeName = f.GetBucketingAttribute()
+	}
+	if len(attributeName) > 0 {
+		if val, ok := ec.attribute(attributeName); ok && val != nil {
+			if key := fmt.Sprint(val); len(key) > 0 {
+				return key
+			}
 		}
-		return f.GetDisabledValue(), false
+		log.Debug(messages.BucketingAttributeMissing, attributeName)
 	}
-	return f.GetDisabledValue(), false
+	return ec.entityID
 }
 func (f *Feature) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(messages.ParsingFeatureRules)
@@ -206,11 +513,19 @@ func (f *Feature) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(rulesMap)
 	return rulesMap
 }
-func (f *Feature) evaluateSegment(segmentKey string, entityAttributes map[string]interface{}) bool {
+func (f *Feature) evaluateSegment(segmentKey string, ec *evaluationContext) bool {
 	log.Debug(messages.EvaluatingSegments)
-	segment, ok := GetCacheInstance().SegmentMap[segmentKey]
+	segment, ok := ec.cacheInstance().SegmentMap[segmentKey]
+	if ec.trace != nil {
+		membership := SegmentMembership{SegmentID: segmentKey}
+		if ok {
+			membership = segment.explain(ec)
+		}
+		ec.trace.addSegment(membership)
+		return membership.Matched
+	}
 	if ok {
-		return segment.EvaluateRule(entityAttributes)
+		return segment.evaluate(ec)
 	}
 	return false
 }
@@ -0,0 +1,176 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ConfigurationVersion : configurations applied by the SDK, kept in the History.
+type ConfigurationVersion struct {
+	// Version numbers the applied configurations, from 1, in the order they were applied.
+	Version   int       `json:"version"`
+	Source    string    `json:"source,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+	AppliedAt time.Time `json:"applied_at"`
+	// Checksum is the hex encoded SHA-256 of the configurations.
+	Checksum string `json:"checksum"`
+	// Snapshot holds the configurations, see ExportSnapshot.
+	Snapshot json.RawMessage `json:"snapshot"`
+}
+
+// History : the last applied configurations, oldest first.
+type History struct {
+	size     int
+	versions []ConfigurationVersion
+	pinned   int
+	mu       sync.RWMutex
+}
+
+// historyFile is the format of a persisted history.
+type historyFile struct {
+	EnvironmentID string                 `json:"environment_id"`
+	CollectionID  string                 `json:"collection_id"`
+	Versions      []ConfigurationVersion `json:"versions"`
+	PinnedVersion int                    `json:"pinned_version,omitempty"`
+}
+
+// NewHistory : Create a history keeping the last size applied configurations
+func NewHistory(size int) *History {
+	return &History{size: size}
+}
+
+// Record : Record the configurations of the cache as a new version, unless they are those of the latest version.
+// recorded is false when the configurations are unchanged, and version is then the latest version.
+func (h *History) Record(cache *Cache, environmentID, collectionID string) (version ConfigurationVersion, recorded bool, err error) {
+	configurations, err := json.Marshal(cache.cacheConfig())
+	if err != nil {
+		return version, false, err
+	}
+	sum := sha256.Sum256(configurations)
+	checksum := hex.EncodeToString(sum[:])
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.versions) > 0 && h.versions[len(h.versions)-1].Checksum == checksum {
+		return h.versions[len(h.versions)-1], false, nil
+	}
+	snapshot, err := ExportSnapshot(cache, environmentID, collectionID)
+	if err != nil {
+		return version, false, err
+	}
+	var compacted bytes.Buffer
+	if err = json.Compact(&compacted, snapshot); err != nil {
+		return version, false, err
+	}
+	version = ConfigurationVersion{
+		Version:   1,
+		Source:    cache.Source,
+		FetchedAt: cache.FetchedAt,
+		AppliedAt: Now(),
+		Checksum:  checksum,
+		Snapshot:  compacted.Bytes(),
+	}
+	if len(h.versions) > 0 {
+		version.Version = h.versions[len(h.versions)-1].Version + 1
+	}
+	h.versions = append(h.versions, version)
+	if len(h.versions) > h.size {
+		h.versions = append([]ConfigurationVersion{}, h.versions[len(h.versions)-h.size:]...)
+	}
+	return version, true, nil
+}
+
+// Versions : Get the recorded versions, oldest first
+func (h *History) Versions() []ConfigurationVersion {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]ConfigurationVersion{}, h.versions...)
+}
+
+// Get : Get a recorded version
+func (h *History) Get(version int) (ConfigurationVersion, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, v := range h.versions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return ConfigurationVersion{}, false
+}
+
+// Latest : Get the latest recorded version
+func (h *History) Latest() (ConfigurationVersion, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.versions) == 0 {
+		return ConfigurationVersion{}, false
+	}
+	return h.versions[len(h.versions)-1], true
+}
+
+// Encode : Encode the history of the environment and the collection, to be persisted
+func (h *History) Encode(environmentID, collectionID string) ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return json.Marshal(historyFile{EnvironmentID: environmentID, CollectionID: collectionID, Versions: h.versions, PinnedVersion: h.pinned})
+}
+
+// Decode : Replace the versions and the pinned version with those of a persisted history. A history of another
+// environment or collection is ignored, and decodes no version. A pinned version no longer in the history is dropped.
+func (h *History) Decode(data []byte, environmentID, collectionID string) error {
+	var file historyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.versions, h.pinned = nil, 0
+	if file.EnvironmentID != environmentID || file.CollectionID != collectionID {
+		return nil
+	}
+	if len(file.Versions) > h.size {
+		file.Versions = file.Versions[len(file.Versions)-h.size:]
+	}
+	h.versions = file.Versions
+	for _, version := range h.versions {
+		if version.Version == file.PinnedVersion {
+			h.pinned = file.PinnedVersion
+		}
+	}
+	return nil
+}
+
+// Pin : Record the version the configurations are pinned to by a rollback, 0 if they are not pinned
+func (h *History) Pin(version int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pinned = version
+}
+
+// Pinned : Get the version the configurations are pinned to, 0 if they are not pinned
+func (h *History) Pinned() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.pinned
+}
@@ -88,11 +88,15 @@ func (p *Property) GetSegmentRules() []SegmentRule {
 // An attribute is a parameter that is used to define a segment. The SDK uses the attribute values to determine if the
 // specified entity satisfies the targeting rules, and returns the appropriate property value.
 func (p *Property) GetCurrentValue(entityID string, entityAttributes ...map[string]interface{}) interface{} {
-	log.Debug(messages.RetrievingProperty)
-	if len(entityID) <= 0 {
-		log.Error("Property evaluation: ", messages.InvalidEntityId, "GetCurrentValue")
-		return nil
-	}
+	return p.GetCurrentValueWithOptions(entityID, EvaluationOptions{}, entityAttributes...)
+}
+
+// GetCurrentValueWithOptions returns the default property value or its overridden value based on the evaluation.
+//
+// It behaves like GetCurrentValue, with the evaluation tuned by options.
+// The entityAttributes may contain AttributeResolver functions, which are invoked with options.Context
+// only when a segment rule references that attribute.
+func (p *Property) GetCurrentValueWithOptions(entityID string, options EvaluationOptions, entityAttributes ...map[string]interface{}) interface{} {
 	var temp map[string]interface{}
 	switch len(entityAttributes) {
 	case 0: // Do Nothing
@@ -103,29 +107,57 @@ func (p *Property) GetCurrentValue(entityID string, entityAttributes ...map[stri
 		return nil
 	}
 
+	return p.currentValue(entityID, mapAttributes(temp), options)
+}
+
+// currentValue evaluates the property for the entity attributes, see GetCurrentValueWithOptions.
+func (p *Property) currentValue(entityID string, entityAttributes attributeSet, options EvaluationOptions) interface{} {
+	log.Debug(messages.RetrievingProperty)
+	if len(entityID) <= 0 {
+		log.Error("Property evaluation: ", messages.InvalidEntityId, "GetCurrentValue")
+		return nil
+	}
 	if p.isPropertyValid() {
-		val := p.propertyEvaluation(entityID, temp)
+		val := p.propertyEvaluation(newEvaluationContext(entityID, entityAttributes, options))
 		return getTypeCastedValue(val, p.GetPropertyDataType(), p.GetPropertyDataFormat())
 	}
 	log.Error("Invalid property. Property struct has empty values for required fields.")
 	return nil
 }
 
+// GetCurrentValueFor returns the default property value or its overridden value based on the evaluation.
+//
+// It behaves like GetCurrentValue, but the entity attributes are read from entity, which can be any of
+//  1. a map of type `map[string]interface{}`
+//  2. a value implementing the AttributeProvider interface
+//  3. a struct, or a pointer to a struct, whose fields are tagged with `appconfig:"attributeName"`
+func (p *Property) GetCurrentValueFor(entityID string, entity interface{}) interface{} {
+	entityAttributes, err := entityAttributesOf(entity)
+	if err != nil {
+		log.Error("Property evaluation: ", messages.InvalidEntity, "GetCurrentValueFor. ", err.Error())
+		return nil
+	}
+	return p.currentValue(entityID, entityAttributes, EvaluationOptions{})
+}
+
 func (p *Property) isPropertyValid() bool {
 	return !(p.Name == "" || p.PropertyID == "" || p.DataType == "" || p.Value == nil)
 }
 
-func (p *Property) propertyEvaluation(entityID string, entityAttributes map[string]interface{}) interface{} {
+func (p *Property) propertyEvaluation(ec *evaluationContext) interface{} {
 
+	entityID := ec.entityID
 	var evaluatedSegmentID string = constants.DefaultSegmentID
 	defer func() {
-		utils.GetMeteringInstance().RecordEvaluation("", p.GetPropertyID(), entityID, evaluatedSegmentID)
+		if !ec.dryRun {
+			utils.GetMeteringInstance().RecordEvaluation("", p.GetPropertyID(), entityID, evaluatedSegmentID)
+		}
 	}()
 
 	log.Debug(messages.EvaluatingProperty)
 	defer utils.GracefullyHandleError()
 
-	if len(p.GetSegmentRules()) > 0 && len(entityAttributes) > 0 {
+	if len(p.GetSegmentRules()) > 0 && !ec.attributes.isEmpty() {
 		var rulesMap map[int]SegmentRule
 		rulesMap = p.parseRules(p.GetSegmentRules())
 
@@ -141,7 +173,7 @@ func (p *Property) propertyEvaluation(entityID string, entityAttributes map[stri
 			segmentRule := rulesMap[k]
 			for _, rule := range segmentRule.GetRules() {
 				for _, segmentKey := range rule.Segments {
-					if p.evaluateSegment(string(segmentKey), entityAttributes) {
+					if p.evaluateSegment(string(segmentKey), ec) {
 						evaluatedSegmentID = segmentKey
 						if segmentRule.GetValue() == "$default" {
 							log.Debug(messages.PropertyValue, p.GetValue())
@@ -169,11 +201,11 @@ func (p *Property) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(rulesMap)
 	return rulesMap
 }
-func (p *Property) evaluateSegment(segmentKey string, entityAttributes map[string]interface{}) bool {
+func (p *Property) evaluateSegment(segmentKey string, ec *evaluationContext) bool {
 	log.Debug(messages.EvaluatingSegments)
-	segment, ok := GetCacheInstance().SegmentMap[segmentKey]
+	segment, ok := ec.cacheInstance().SegmentMap[segmentKey]
 	if ok {
-		return segment.EvaluateRule(entityAttributes)
+		return segment.evaluate(ec)
 	}
 	return false
 }
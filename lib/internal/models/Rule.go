@@ -21,10 +21,40 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
+// supportedOperators : the operators understood by operatorCheck
+var supportedOperators = []string{
+	"endsWith", "notEndsWith", "startsWith", "notStartsWith", "contains", "notContains", "is", "isNot",
+	"greaterThan", "lesserThan", "greaterThanEquals", "lesserThanEquals",
+	"isIgnoreCase", "containsIgnoreCase", "startsWithIgnoreCase", "endsWithIgnoreCase",
+}
+
+// numericOperators : the operators whose rule values must be numbers
+var numericOperators = []string{"greaterThan", "lesserThan", "greaterThanEquals", "lesserThanEquals"}
+
+// negativeOperators : the operators that an attribute must satisfy for every rule value, instead of any of them
+var negativeOperators = []string{"isNot", "notContains", "notStartsWith", "notEndsWith"}
+
+// unicodeNormalization : when enabled, strings are compared in their Unicode NFC form
+var unicodeNormalization atomic.Bool
+
+// SetUnicodeNormalization : Enable or disable the NFC normalization of the attribute values and rule values
+// compared by the string operators. It is disabled by default.
+func SetUnicodeNormalization(enabled bool) {
+	unicodeNormalization.Store(enabled)
+}
+
+// foldCase returns the case folded form of str, used by the case-insensitive operators.
+func foldCase(str string) string {
+	return cases.Fold().String(str)
+}
+
 // Rule : Rule struct
 type Rule struct {
 	Values        []interface{} `json:"values"`
@@ -55,6 +85,15 @@ func (r *Rule) operatorCheck(key interface{}, value interface{}) bool {
 		return result
 	}
 
+	if unicodeNormalization.Load() {
+		if k, ok := key.(string); ok {
+			key = norm.NFC.String(k)
+		}
+		if v, ok := value.(string); ok {
+			value = norm.NFC.String(v)
+		}
+	}
+
 	switch r.GetOperator() {
 	case "endsWith":
 		result = strings.HasSuffix(key.(string), value.(string))
@@ -104,6 +143,22 @@ func (r *Rule) operatorCheck(key interface{}, value interface{}) bool {
 			result = !(key == value)
 		}
 		break
+	case "isIgnoreCase":
+		if isString(key) {
+			result = foldCase(key.(string)) == foldCase(value.(string))
+		} else {
+			result = (&Rule{Operator: "is"}).operatorCheck(key, value)
+		}
+		break
+	case "containsIgnoreCase":
+		result = strings.Contains(foldCase(key.(string)), foldCase(value.(string)))
+		break
+	case "startsWithIgnoreCase":
+		result = strings.HasPrefix(foldCase(key.(string)), foldCase(value.(string)))
+		break
+	case "endsWithIgnoreCase":
+		result = strings.HasSuffix(foldCase(key.(string)), foldCase(value.(string)))
+		break
 	case "greaterThan":
 		if isNumber(key) {
 			key, _ = getFloat(key)
@@ -213,13 +268,16 @@ func getFloat(unk interface{}) (float64, error) {
 
 // EvaluateRule : Evaluate Rule
 func (r *Rule) EvaluateRule(entityAttributes map[string]interface{}) bool {
+	return r.evaluate(newEvaluationContext("", mapAttributes(entityAttributes), EvaluationOptions{}))
+}
+
+func (r *Rule) evaluate(ec *evaluationContext) bool {
 	defer utils.GracefullyHandleError()
 	var result = false
-	key, ok := entityAttributes[r.GetAttributeName()]
+	key, ok := ec.attribute(r.GetAttributeName())
 	if !ok {
 		return false
 	}
-	negativeOperators := []string{"isNot", "notContains", "notStartsWith", "notEndsWith"}
 	if slices.Contains(negativeOperators, r.Operator) {
 		result = true
 		for _, val := range r.GetValues() {
@@ -0,0 +1,88 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Schedule : the activation window and the rollout ramp of a feature flag, evaluated against the SDK clock.
+// The times are RFC 3339 timestamps, e.g. "2026-03-02T09:00:00Z".
+type Schedule struct {
+	// ActiveFrom is the time from which the feature flag is active. Before it, the disabled value is served.
+	ActiveFrom *time.Time `json:"active_from,omitempty"`
+	// ActiveUntil is the time from which the feature flag is no longer active, and the disabled value is served.
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	// Ramp replaces the rollout percentage of the feature flag with a percentage growing over time.
+	Ramp *Ramp `json:"ramp,omitempty"`
+}
+
+// Ramp : a rollout percentage growing linearly from FromPercentage at Start to ToPercentage at End.
+// Before Start the rollout percentage is FromPercentage, after End it is ToPercentage.
+type Ramp struct {
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	FromPercentage int       `json:"from_percentage"`
+	ToPercentage   int       `json:"to_percentage"`
+}
+
+var clockMu sync.RWMutex
+
+// SetClock : Set the clock against which the schedules are evaluated. nil restores the system clock.
+// All the instances of an application sharing a clock source roll out identically.
+func SetClock(clock func() time.Time) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	if clock == nil {
+		clock = time.Now
+	}
+	timeNow = clock
+}
+
+// Now : Get the time of the SDK clock, see SetClock.
+func Now() time.Time {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return timeNow()
+}
+
+// isActive tells whether the activation window of the schedule contains t.
+func (s *Schedule) isActive(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	if s.ActiveFrom != nil && t.Before(*s.ActiveFrom) {
+		return false
+	}
+	if s.ActiveUntil != nil && !t.Before(*s.ActiveUntil) {
+		return false
+	}
+	return true
+}
+
+// percentageAt returns the rollout percentage of the ramp at t, rounded down to a whole percentage.
+func (r *Ramp) percentageAt(t time.Time) int {
+	switch {
+	case !t.After(r.Start):
+		return r.FromPercentage
+	case !t.Before(r.End):
+		return r.ToPercentage
+	}
+	elapsed := float64(t.Sub(r.Start)) / float64(r.End.Sub(r.Start))
+	return r.FromPercentage + int(elapsed*float64(r.ToPercentage-r.FromPercentage))
+}
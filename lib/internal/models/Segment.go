@@ -46,10 +46,14 @@ func (s *Segment) GetRules() []Rule {
 
 // EvaluateRule : Evaluate Rule
 func (s *Segment) EvaluateRule(entityAttributes map[string]interface{}) bool {
+	return s.evaluate(newEvaluationContext("", mapAttributes(entityAttributes), EvaluationOptions{}))
+}
+
+func (s *Segment) evaluate(ec *evaluationContext) bool {
 	log.Debug(messages.EvalSegmentRule)
 	defer utils.GracefullyHandleError()
 	for _, rule := range s.GetRules() {
-		if !rule.EvaluateRule(entityAttributes) {
+		if !rule.evaluate(ec) {
 			return false
 		}
 	}
@@ -0,0 +1,106 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+)
+
+// RuleResult : the outcome of a segment rule for an entity.
+type RuleResult struct {
+	AttributeName string        `json:"attribute_name"`
+	Operator      string        `json:"operator"`
+	Values        []interface{} `json:"values"`
+	// AttributeValue is the value of the attribute in the entity attributes, nil if AttributePresent is false.
+	AttributeValue   interface{} `json:"attribute_value"`
+	AttributePresent bool        `json:"attribute_present"`
+	Passed           bool        `json:"passed"`
+	// Reason explains why the rule passed or failed.
+	Reason string `json:"reason"`
+}
+
+// SegmentMembership : whether an entity belongs to a segment, with the outcome of each of its rules.
+// The entity belongs to the segment when all the rules pass.
+type SegmentMembership struct {
+	SegmentID string       `json:"segment_id"`
+	Name      string       `json:"name"`
+	Matched   bool         `json:"matched"`
+	Rules     []RuleResult `json:"rules"`
+}
+
+// GetSegmentsForEntity returns the membership of the entity in every segment of the segment map, sorted by segment ID.
+// Unlike the feature flag evaluation, every rule of a segment is evaluated, so that all the failing rules are reported.
+func GetSegmentsForEntity(segmentMap map[string]Segment, entityID string, entityAttributes map[string]interface{}) []SegmentMembership {
+	ec := newEvaluationContext(entityID, mapAttributes(entityAttributes), EvaluationOptions{})
+	memberships := make([]SegmentMembership, 0, len(segmentMap))
+	for _, segment := range segmentMap {
+		memberships = append(memberships, segment.explain(ec))
+	}
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].SegmentID < memberships[j].SegmentID })
+	return memberships
+}
+
+// explain evaluates every rule of the segment for the entity.
+func (s *Segment) explain(ec *evaluationContext) SegmentMembership {
+	membership := SegmentMembership{SegmentID: s.GetSegmentID(), Name: s.GetName(), Matched: true}
+	for _, rule := range s.GetRules() {
+		result := rule.explain(ec)
+		membership.Matched = membership.Matched && result.Passed
+		membership.Rules = append(membership.Rules, result)
+	}
+	return membership
+}
+
+// explain evaluates the rule for the entity, like evaluate, and tells why it passed or failed.
+func (r *Rule) explain(ec *evaluationContext) RuleResult {
+	result := RuleResult{AttributeName: r.GetAttributeName(), Operator: r.GetOperator(), Values: r.GetValues()}
+	key, ok := ec.attribute(r.GetAttributeName())
+	if !ok {
+		result.Reason = "the attribute is not present in the entity attributes"
+		return result
+	}
+	result.AttributeValue, result.AttributePresent = key, true
+	result.Passed = r.evaluate(ec)
+	negative := slices.Contains(negativeOperators, r.Operator)
+	for _, val := range r.GetValues() {
+		matched := r.safeOperatorCheck(key, val)
+		switch {
+		case negative && !matched:
+			result.Reason = fmt.Sprintf("%v fails %s %v", key, r.Operator, val)
+			return result
+		case !negative && matched:
+			result.Reason = fmt.Sprintf("%v %s %v", key, r.Operator, val)
+			return result
+		}
+	}
+	if negative {
+		result.Reason = fmt.Sprintf("%v %s each of the values", key, r.Operator)
+	} else {
+		result.Reason = fmt.Sprintf("%v does not satisfy %s for any of the values", key, r.Operator)
+	}
+	return result
+}
+
+// safeOperatorCheck is operatorCheck, treating a value that cannot be compared (e.g. a number with startsWith) as a mismatch.
+func (r *Rule) safeOperatorCheck(key, value interface{}) (result bool) {
+	defer utils.GracefullyHandleError()
+	return r.operatorCheck(key, value)
+}
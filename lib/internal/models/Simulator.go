@@ -0,0 +1,231 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SimulationEntity : an entity, and its attributes, evaluated by Simulate.
+type SimulationEntity struct {
+	EntityID   string                 `json:"entity_id"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// SimulationChange : an entity whose value of a feature flag differs between the two simulated configurations.
+type SimulationChange struct {
+	EntityID string      `json:"entity_id"`
+	Before   interface{} `json:"before"`
+	After    interface{} `json:"after"`
+}
+
+// FeatureSimulation : the simulated values of a feature flag.
+type FeatureSimulation struct {
+	FeatureID string `json:"feature_id"`
+	// Distribution counts the entities per value, in the baseline configuration. Values are keyed by their JSON encoding.
+	Distribution map[string]int `json:"distribution"`
+	// CompareDistribution counts the entities per value, in the compared configuration.
+	CompareDistribution map[string]int `json:"compare_distribution,omitempty"`
+	// Changes are the entities whose value differs between the configurations, in the order of the entities.
+	Changes []SimulationChange `json:"changes,omitempty"`
+}
+
+// SimulationReport : the result of Simulate, with the feature flags sorted by feature ID.
+type SimulationReport struct {
+	Entities int                 `json:"entities"`
+	Features []FeatureSimulation `json:"features"`
+}
+
+// Simulate evaluates every feature flag of the baseline configuration for each of the entities, and reports the
+// distribution of the values. When compare is not nil, the feature flags of the compare configuration are evaluated
+// too, and the entities whose value changes are reported.
+//
+// The configurations have the format of a bootstrap file. The evaluations use the same rules, hashing and rollout
+// buckets as the live evaluations, but do not touch the SDK cache, the usage metering, the sticky assignments or
+// the exposure events. A feature flag absent from one of the configurations evaluates to nil in that configuration.
+func Simulate(baseline, compare []byte, environmentID, collectionID string, entities []SimulationEntity) (SimulationReport, error) {
+	report := SimulationReport{Entities: len(entities)}
+	baselineCache, err := newCacheFromConfig(baseline, environmentID, collectionID)
+	if err != nil {
+		return report, errors.New("baseline configuration: " + err.Error())
+	}
+	var compareCache *Cache
+	if compare != nil {
+		if compareCache, err = newCacheFromConfig(compare, environmentID, collectionID); err != nil {
+			return report, errors.New("compare configuration: " + err.Error())
+		}
+	}
+
+	featureIDs := make(map[string]bool)
+	for featureID := range baselineCache.FeatureMap {
+		featureIDs[featureID] = true
+	}
+	if compareCache != nil {
+		for featureID := range compareCache.FeatureMap {
+			featureIDs[featureID] = true
+		}
+	}
+	sortedIDs := make([]string, 0, len(featureIDs))
+	for featureID := range featureIDs {
+		sortedIDs = append(sortedIDs, featureID)
+	}
+	sort.Strings(sortedIDs)
+
+	for _, featureID := range sortedIDs {
+		simulation := FeatureSimulation{FeatureID: featureID, Distribution: make(map[string]int)}
+		if compareCache != nil {
+			simulation.CompareDistribution = make(map[string]int)
+		}
+		for _, entity := range entities {
+			before := simulateFeature(baselineCache, featureID, entity)
+			simulation.Distribution[distributionKey(before)]++
+			if compareCache == nil {
+				continue
+			}
+			after := simulateFeature(compareCache, featureID, entity)
+			simulation.CompareDistribution[distributionKey(after)]++
+			if distributionKey(before) != distributionKey(after) {
+				simulation.Changes = append(simulation.Changes, SimulationChange{EntityID: entity.EntityID, Before: before, After: after})
+			}
+		}
+		report.Features = append(report.Features, simulation)
+	}
+	return report, nil
+}
+
+// simulateFeature evaluates the feature flag of the cache for the entity, without side effects.
+func simulateFeature(cache *Cache, featureID string, entity SimulationEntity) interface{} {
+	feature, ok := cache.FeatureMap[featureID]
+	if !ok || !feature.isFeatureValid() {
+		return nil
+	}
+	ec := newEvaluationContext(entity.EntityID, mapAttributes(entity.Attributes), EvaluationOptions{})
+	ec.cache, ec.dryRun, ec.ignoreAssignments = cache, true, true
+	return getTypeCastedValue(feature.featureEvaluation(ec).Value, feature.GetFeatureDataType(), feature.GetFeatureDataFormat())
+}
+
+func distributionKey(value interface{}) string {
+	key, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(key)
+}
+
+// newCacheFromConfig builds a cache, independent of the SDK cache, from a configuration in the bootstrap file format.
+func newCacheFromConfig(data []byte, environmentID, collectionID string) (*Cache, error) {
+	extracted, err := ExtractConfigurations(data, environmentID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	configurations := CacheConfig{}
+	if err := json.Unmarshal(extracted, &configurations); err != nil {
+		return nil, err
+	}
+	cache := &Cache{
+		FeatureMap:       make(map[string]Feature),
+		PropertyMap:      make(map[string]Property),
+		SegmentMap:       make(map[string]Segment),
+		SecretManagerMap: make(map[string]interface{}),
+	}
+	for _, feature := range configurations.Features {
+		cache.FeatureMap[feature.GetFeatureID()] = feature.Feature
+	}
+	for _, property := range configurations.Properties {
+		cache.PropertyMap[property.GetPropertyID()] = property.Property
+	}
+	for _, segment := range configurations.Segments {
+		cache.SegmentMap[segment.GetSegmentID()] = segment
+	}
+	return cache, nil
+}
+
+// ReadSimulationEntities reads the entities of a simulation. The format is either
+//  1. "csv": a header row naming the columns, one of which is "entity_id" (or "id"), the other columns being
+//     attributes. Empty cells are absent attributes. Values are read as strings.
+//  2. "jsonl": one JSON object per line, e.g. {"entity_id": "user1", "attributes": {"country": "India", "age": 32}}.
+func ReadSimulationEntities(r io.Reader, format string) ([]SimulationEntity, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return readCSVEntities(r)
+	case "jsonl", "ndjson":
+		return readJSONLEntities(r)
+	}
+	return nil, errors.New("unsupported entities format " + format + ", expected csv or jsonl")
+}
+
+func readCSVEntities(r io.Reader) ([]SimulationEntity, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	idColumn := -1
+	for i, column := range header {
+		if column == "entity_id" || (column == "id" && idColumn < 0) {
+			idColumn = i
+		}
+	}
+	if idColumn < 0 {
+		return nil, errors.New("the CSV header has no entity_id column")
+	}
+	entities := make([]SimulationEntity, 0, len(records)-1)
+	for line, record := range records[1:] {
+		entity := SimulationEntity{EntityID: record[idColumn], Attributes: make(map[string]interface{})}
+		if len(entity.EntityID) == 0 {
+			return nil, fmt.Errorf("line %d: empty entity id", line+2)
+		}
+		for i, value := range record {
+			if i != idColumn && len(value) > 0 {
+				entity.Attributes[header[i]] = value
+			}
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+func readJSONLEntities(r io.Reader) ([]SimulationEntity, error) {
+	var entities []SimulationEntity
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		}
+		var entity SimulationEntity
+		if err := json.Unmarshal([]byte(text), &entity); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		if len(entity.EntityID) == 0 {
+			return nil, fmt.Errorf("line %d: empty entity id", line)
+		}
+		entities = append(entities, entity)
+	}
+	return entities, scanner.Err()
+}
@@ -0,0 +1,116 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+)
+
+// Snapshot : the configurations served by the SDK, in the Config format, with their metadata.
+// A snapshot is a valid bootstrap file.
+type Snapshot struct {
+	Config
+	Metadata SnapshotMetadata `json:"metadata"`
+}
+
+// SnapshotMetadata : where the configurations of a snapshot come from.
+type SnapshotMetadata struct {
+	EnvironmentID string `json:"environment_id"`
+	CollectionID  string `json:"collection_id"`
+	// Source and FetchedAt are the Source and FetchedAt of the exported cache.
+	Source     string     `json:"source,omitempty"`
+	FetchedAt  *time.Time `json:"fetched_at,omitempty"`
+	ExportedAt time.Time  `json:"exported_at"`
+	SDKVersion string     `json:"sdk_version"`
+}
+
+// ExportSnapshot : Export the configurations of the cache as an indented snapshot
+func ExportSnapshot(cache *Cache, environmentID, collectionID string) ([]byte, error) {
+	if cache == nil || cache.FeatureMap == nil {
+		return nil, errors.New("no configurations are loaded")
+	}
+	configurations, err := json.Marshal(cache.cacheConfig())
+	if err != nil {
+		return nil, err
+	}
+	snapshot := Snapshot{Metadata: SnapshotMetadata{
+		EnvironmentID: environmentID,
+		CollectionID:  collectionID,
+		Source:        cache.Source,
+		ExportedAt:    Now().UTC(),
+		SDKVersion:    constants.SDKVersion,
+	}}
+	if !cache.FetchedAt.IsZero() {
+		fetchedAt := cache.FetchedAt.UTC()
+		snapshot.Metadata.FetchedAt = &fetchedAt
+	}
+	if err = json.Unmarshal(FormatConfig(configurations, environmentID, collectionID), &snapshot.Config); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(snapshot, "", "  ")
+}
+
+// ReadSnapshot : Extract the configurations of a snapshot, see ExtractConfigurations. The configurations are those of
+// the environment and the collection of the snapshot metadata, or of environmentID and collectionID for a bootstrap
+// file without metadata.
+func ReadSnapshot(data []byte, environmentID, collectionID string) ([]byte, SnapshotMetadata, error) {
+	var snapshot struct {
+		Metadata *SnapshotMetadata `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, SnapshotMetadata{}, errors.New("failed to parse snapshot: " + err.Error())
+	}
+	metadata := SnapshotMetadata{EnvironmentID: environmentID, CollectionID: collectionID}
+	if snapshot.Metadata != nil {
+		metadata = *snapshot.Metadata
+	}
+	configurations, err := ExtractConfigurations(data, metadata.EnvironmentID, metadata.CollectionID)
+	return configurations, metadata, err
+}
+
+// cacheConfig returns the configurations of the cache, sorted by ID.
+func (c *Cache) cacheConfig() CacheConfig {
+	configurations := CacheConfig{
+		Features:   make([]FeatureC, 0, len(c.FeatureMap)),
+		Properties: make([]PropertyC, 0, len(c.PropertyMap)),
+		Segments:   make([]Segment, 0, len(c.SegmentMap)),
+	}
+	for _, feature := range c.FeatureMap {
+		configurations.Features = append(configurations.Features, FeatureC{Feature: feature})
+	}
+	for _, property := range c.PropertyMap {
+		configurations.Properties = append(configurations.Properties, PropertyC{Property: property})
+	}
+	for _, segment := range c.SegmentMap {
+		configurations.Segments = append(configurations.Segments, segment)
+	}
+	sort.Slice(configurations.Features, func(i, j int) bool {
+		return configurations.Features[i].GetFeatureID() < configurations.Features[j].GetFeatureID()
+	})
+	sort.Slice(configurations.Properties, func(i, j int) bool {
+		return configurations.Properties[i].GetPropertyID() < configurations.Properties[j].GetPropertyID()
+	})
+	sort.Slice(configurations.Segments, func(i, j int) bool {
+		return configurations.Segments[i].GetSegmentID() < configurations.Segments[j].GetSegmentID()
+	})
+	return configurations
+}
@@ -0,0 +1,470 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SeverityError : the configuration item cannot be evaluated correctly.
+const SeverityError = "error"
+
+// SeverityWarning : the configuration item can be evaluated, but probably not as intended.
+const SeverityWarning = "warning"
+
+// ValidationIssue : a single finding of the configuration validator.
+type ValidationIssue struct {
+	Severity      string `json:"severity"`
+	Kind          string `json:"kind"` // one of configuration, feature, property or segment
+	ID            string `json:"id,omitempty"`
+	EnvironmentID string `json:"environment_id,omitempty"`
+	Message       string `json:"message"`
+}
+
+// String : Human readable form of the issue.
+func (vi ValidationIssue) String() string {
+	var sb strings.Builder
+	sb.WriteString(vi.Severity + ": " + vi.Kind)
+	if len(vi.ID) > 0 {
+		sb.WriteString(" " + vi.ID)
+	}
+	if len(vi.EnvironmentID) > 0 {
+		sb.WriteString(" (environment " + vi.EnvironmentID + ")")
+	}
+	sb.WriteString(": " + vi.Message)
+	return sb.String()
+}
+
+// ValidationReport : the errors and warnings found in a configuration.
+type ValidationReport struct {
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// IsValid returns true when the report has no errors. Warnings do not make a configuration invalid.
+func (vr *ValidationReport) IsValid() bool {
+	return len(vr.Errors) == 0
+}
+
+// String : Human readable form of the report, one issue per line.
+func (vr *ValidationReport) String() string {
+	lines := make([]string, 0, len(vr.Errors)+len(vr.Warnings))
+	for _, issue := range vr.Errors {
+		lines = append(lines, issue.String())
+	}
+	for _, issue := range vr.Warnings {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (vr *ValidationReport) add(severity, kind, id, environmentID, message string) {
+	issue := ValidationIssue{
+		Severity:      severity,
+		Kind:          kind,
+		ID:            id,
+		EnvironmentID: environmentID,
+		Message:       message,
+	}
+	if severity == SeverityError {
+		vr.Errors = append(vr.Errors, issue)
+	} else {
+		vr.Warnings = append(vr.Warnings, issue)
+	}
+}
+
+// Validate checks a configuration in the models.Config format (bootstrap file, persistent cache or API response)
+// for data that the evaluation would otherwise only discover at evaluation time, such as non-string rule values,
+// unknown operators, non-numeric rollout percentages and segment ids that do not exist.
+func Validate(config []byte) ValidationReport {
+	report := ValidationReport{}
+	c := Config{}
+	if err := json.Unmarshal(config, &c); err != nil {
+		report.add(SeverityError, "configuration", "", "", "failed to parse configurations: "+err.Error())
+		return report
+	}
+	return validateConfig(report, c)
+}
+
+// ValidateFor is Validate restricted to the configurations served to a client of the environment and collection:
+// the features and properties of the collection in the environment, and the segments they reference. Errors in the
+// other environments and collections do not make the configurations invalid for the client.
+func ValidateFor(config []byte, environmentID, collectionID string) ValidationReport {
+	report := ValidationReport{}
+	c := Config{}
+	if err := json.Unmarshal(config, &c); err != nil {
+		report.add(SeverityError, "configuration", "", "", "failed to parse configurations: "+err.Error())
+		return report
+	}
+	return validateConfig(report, scopeConfig(c, environmentID, collectionID))
+}
+
+// scopeConfig returns the environment of the configurations with only the features and properties of the collection,
+// and the segments they reference, as selected by ExtractConfigurations.
+func scopeConfig(c Config, environmentID, collectionID string) Config {
+	scoped := Config{}
+	referenced := make(map[string]bool)
+	addReferences := func(segmentRules []SegmentRule) {
+		for _, segmentRule := range segmentRules {
+			for _, ruleElem := range segmentRule.Rules {
+				for _, segmentID := range ruleElem.Segments {
+					referenced[segmentID] = true
+				}
+			}
+		}
+	}
+	for _, env := range c.Environments {
+		if env.EnvironmentID != environmentID {
+			continue
+		}
+		environment := Environment{Name: env.Name, EnvironmentID: env.EnvironmentID}
+		for _, feature := range env.Features {
+			if inCollection(feature.Collections, collectionID) {
+				environment.Features = append(environment.Features, feature)
+				addReferences(feature.SegmentRules)
+			}
+		}
+		for _, property := range env.Properties {
+			if inCollection(property.Collections, collectionID) {
+				environment.Properties = append(environment.Properties, property)
+				addReferences(property.SegmentRules)
+			}
+		}
+		scoped.Environments = append(scoped.Environments, environment)
+		break
+	}
+	for _, segment := range c.Segments {
+		if referenced[segment.SegmentID] {
+			scoped.Segments = append(scoped.Segments, segment)
+		}
+	}
+	return scoped
+}
+
+// inCollection reports whether an entry belongs to the collection. An entry without collections belongs to all of them.
+func inCollection(collections []Collection, collectionID string) bool {
+	if collections == nil {
+		return true
+	}
+	for _, collection := range collections {
+		if collection.CollectionID == collectionID {
+			return true
+		}
+	}
+	return false
+}
+
+func validateConfig(report ValidationReport, c Config) ValidationReport {
+
+	segmentIDs := make(map[string]bool)
+	for i, segment := range c.Segments {
+		if len(segment.SegmentID) == 0 {
+			report.add(SeverityError, "segment", "", "", fmt.Sprintf("segments[%d] has no segment_id", i))
+			continue
+		}
+		if segmentIDs[segment.SegmentID] {
+			report.add(SeverityWarning, "segment", segment.SegmentID, "", "duplicate segment id, only the first definition is used")
+		}
+		segmentIDs[segment.SegmentID] = true
+		validateSegment(&report, segment)
+	}
+
+	for _, env := range c.Environments {
+		featureIDs := make(map[string]bool)
+		for _, feature := range env.Features {
+			if featureIDs[feature.FeatureID] {
+				report.add(SeverityWarning, "feature", feature.FeatureID, env.EnvironmentID, "duplicate feature id")
+			}
+			featureIDs[feature.FeatureID] = true
+			validateFeature(&report, feature.Feature, env.EnvironmentID, segmentIDs)
+		}
+		validatePrerequisites(&report, env)
+		propertyIDs := make(map[string]bool)
+		for _, property := range env.Properties {
+			if propertyIDs[property.PropertyID] {
+				report.add(SeverityWarning, "property", property.PropertyID, env.EnvironmentID, "duplicate property id")
+			}
+			propertyIDs[property.PropertyID] = true
+			validateProperty(&report, property.Property, env.EnvironmentID, segmentIDs)
+		}
+	}
+	return report
+}
+
+func validateFeature(report *ValidationReport, f Feature, environmentID string, segmentIDs map[string]bool) {
+	id := f.FeatureID
+	if len(id) == 0 {
+		report.add(SeverityError, "feature", f.Name, environmentID, "feature_id is missing")
+	}
+	if len(f.Name) == 0 {
+		report.add(SeverityWarning, "feature", id, environmentID, "name is missing")
+	}
+	if !IsValidDataType(f.DataType) || f.DataType == "SECRETREF" {
+		report.add(SeverityError, "feature", id, environmentID, "invalid type "+strconv.Quote(f.DataType))
+	} else {
+		format := f.GetFeatureDataFormat()
+		if problem := checkValue(f.EnabledValue, f.DataType, format); len(problem) > 0 {
+			report.add(SeverityError, "feature", id, environmentID, "enabled_value "+problem)
+		}
+		if problem := checkValue(f.DisabledValue, f.DataType, format); len(problem) > 0 {
+			report.add(SeverityError, "feature", id, environmentID, "disabled_value "+problem)
+		}
+	}
+	if f.RolloutPercentage != nil && (*f.RolloutPercentage < 0 || *f.RolloutPercentage > 100) {
+		report.add(SeverityError, "feature", id, environmentID, fmt.Sprintf("rollout_percentage %d is not between 0 and 100", *f.RolloutPercentage))
+	}
+	validateVariants(report, f, environmentID)
+	validateSchedule(report, f, environmentID)
+	validateSegmentRules(report, "feature", id, environmentID, f.SegmentRules, f.DataType, f.GetFeatureDataFormat(), true, segmentIDs)
+}
+
+func validateVariants(report *ValidationReport, f Feature, environmentID string) {
+	id := f.FeatureID
+	keys := make(map[string]bool)
+	totalWeight := 0
+	for i, variant := range f.Variants {
+		location := fmt.Sprintf("variants[%d]", i)
+		if len(variant.Key) == 0 {
+			report.add(SeverityError, "feature", id, environmentID, location+".key is missing")
+		} else if keys[variant.Key] {
+			report.add(SeverityError, "feature", id, environmentID, location+" has duplicate key "+strconv.Quote(variant.Key))
+		}
+		keys[variant.Key] = true
+		if variant.Weight < 0 {
+			report.add(SeverityError, "feature", id, environmentID, fmt.Sprintf("%s.weight %d is negative", location, variant.Weight))
+		} else {
+			totalWeight += variant.Weight
+		}
+		if IsValidDataType(f.DataType) {
+			if problem := checkValue(variant.Value, f.DataType, f.GetFeatureDataFormat()); len(problem) > 0 {
+				report.add(SeverityError, "feature", id, environmentID, location+".value "+problem)
+			}
+		}
+	}
+	if len(f.Variants) > 0 && totalWeight == 0 {
+		report.add(SeverityWarning, "feature", id, environmentID, "variants have no positive weight, the enabled value is served instead")
+	}
+}
+
+func validateSchedule(report *ValidationReport, f Feature, environmentID string) {
+	s := f.Schedule
+	if s == nil {
+		return
+	}
+	id := f.FeatureID
+	if s.ActiveFrom != nil && s.ActiveUntil != nil && !s.ActiveFrom.Before(*s.ActiveUntil) {
+		report.add(SeverityError, "feature", id, environmentID, "schedule.active_until is not after schedule.active_from")
+	}
+	if s.Ramp == nil {
+		return
+	}
+	if !s.Ramp.Start.Before(s.Ramp.End) {
+		report.add(SeverityError, "feature", id, environmentID, "schedule.ramp.end is not after schedule.ramp.start")
+	}
+	if s.Ramp.FromPercentage < 0 || s.Ramp.FromPercentage > 100 {
+		report.add(SeverityError, "feature", id, environmentID, fmt.Sprintf("schedule.ramp.from_percentage %d is not between 0 and 100", s.Ramp.FromPercentage))
+	}
+	if s.Ramp.ToPercentage < 0 || s.Ramp.ToPercentage > 100 {
+		report.add(SeverityError, "feature", id, environmentID, fmt.Sprintf("schedule.ramp.to_percentage %d is not between 0 and 100", s.Ramp.ToPercentage))
+	}
+}
+
+// validatePrerequisites checks that the prerequisites of the features of an environment reference existing features
+// with values of their type, and that no feature depends on itself, directly or through other features.
+func validatePrerequisites(report *ValidationReport, env Environment) {
+	features := make(map[string]Feature)
+	for _, feature := range env.Features {
+		if _, ok := features[feature.FeatureID]; !ok {
+			features[feature.FeatureID] = feature.Feature
+		}
+	}
+	for _, feature := range env.Features {
+		for i, prerequisite := range feature.Prerequisites {
+			location := fmt.Sprintf("prerequisites[%d]", i)
+			required, ok := features[prerequisite.FeatureID]
+			if !ok {
+				report.add(SeverityError, "feature", feature.FeatureID, env.EnvironmentID, location+" references unknown feature "+strconv.Quote(prerequisite.FeatureID))
+				continue
+			}
+			if problem := checkValue(prerequisite.Value, required.DataType, required.GetFeatureDataFormat()); len(problem) > 0 {
+				report.add(SeverityError, "feature", feature.FeatureID, env.EnvironmentID, location+".value "+problem)
+			}
+		}
+	}
+
+	// depth-first search of the prerequisite graph, reporting each cycle once
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(featureID string)
+	visit = func(featureID string) {
+		state[featureID] = visiting
+		path = append(path, featureID)
+		for _, prerequisite := range features[featureID].Prerequisites {
+			if _, ok := features[prerequisite.FeatureID]; !ok {
+				continue
+			}
+			switch state[prerequisite.FeatureID] {
+			case unvisited:
+				visit(prerequisite.FeatureID)
+			case visiting:
+				for i := range path {
+					if path[i] == prerequisite.FeatureID {
+						cycle := append(append([]string{}, path[i:]...), prerequisite.FeatureID)
+						report.add(SeverityError, "feature", prerequisite.FeatureID, env.EnvironmentID, "cyclic prerequisites "+strings.Join(cycle, " -> "))
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[featureID] = visited
+	}
+	for _, feature := range env.Features {
+		if state[feature.FeatureID] == unvisited {
+			visit(feature.FeatureID)
+		}
+	}
+}
+
+func validateProperty(report *ValidationReport, p Property, environmentID string, segmentIDs map[string]bool) {
+	id := p.PropertyID
+	if len(id) == 0 {
+		report.add(SeverityError, "property", p.Name, environmentID, "property_id is missing")
+	}
+	if len(p.Name) == 0 {
+		report.add(SeverityWarning, "property", id, environmentID, "name is missing")
+	}
+	if !IsValidDataType(p.DataType) {
+		report.add(SeverityError, "property", id, environmentID, "invalid type "+strconv.Quote(p.DataType))
+	} else if problem := checkValue(p.Value, p.DataType, p.GetPropertyDataFormat()); len(problem) > 0 {
+		report.add(SeverityError, "property", id, environmentID, "value "+problem)
+	}
+	validateSegmentRules(report, "property", id, environmentID, p.SegmentRules, p.DataType, p.GetPropertyDataFormat(), false, segmentIDs)
+}
+
+func validateSegmentRules(report *ValidationReport, kind, id, environmentID string, segmentRules []SegmentRule, dataType, format string, rolloutAllowed bool, segmentIDs map[string]bool) {
+	orders := make(map[int]bool)
+	for i, segmentRule := range segmentRules {
+		location := fmt.Sprintf("segment_rules[%d]", i)
+		if orders[segmentRule.Order] {
+			report.add(SeverityError, kind, id, environmentID, fmt.Sprintf("%s has duplicate order %d, only one of the rules with this order is evaluated", location, segmentRule.Order))
+		}
+		orders[segmentRule.Order] = true
+		if segmentRule.Value != "$default" && IsValidDataType(dataType) {
+			if problem := checkValue(segmentRule.Value, dataType, format); len(problem) > 0 {
+				report.add(SeverityError, kind, id, environmentID, location+".value "+problem)
+			}
+		}
+		if segmentRule.RolloutPercentage != nil {
+			switch rollout := (*segmentRule.RolloutPercentage).(type) {
+			case nil:
+			case string:
+				if rollout != "$default" {
+					report.add(SeverityError, kind, id, environmentID, location+".rollout_percentage "+strconv.Quote(rollout)+" is not a number")
+				}
+			case float64:
+				if rollout < 0 || rollout > 100 {
+					report.add(SeverityError, kind, id, environmentID, fmt.Sprintf("%s.rollout_percentage %v is not between 0 and 100", location, rollout))
+				}
+			default:
+				report.add(SeverityError, kind, id, environmentID, fmt.Sprintf("%s.rollout_percentage %v is not a number", location, rollout))
+			}
+			if !rolloutAllowed {
+				report.add(SeverityWarning, kind, id, environmentID, location+".rollout_percentage is ignored for properties")
+			}
+		}
+		segmentCount := 0
+		for _, ruleElem := range segmentRule.Rules {
+			for _, segmentID := range ruleElem.Segments {
+				segmentCount++
+				if !segmentIDs[segmentID] {
+					report.add(SeverityError, kind, id, environmentID, location+" references unknown segment "+strconv.Quote(segmentID))
+				}
+			}
+		}
+		if segmentCount == 0 {
+			report.add(SeverityWarning, kind, id, environmentID, location+" does not reference any segment and never matches")
+		}
+	}
+}
+
+func validateSegment(report *ValidationReport, s Segment) {
+	if len(s.Rules) == 0 {
+		report.add(SeverityWarning, "segment", s.SegmentID, "", "segment has no rules and matches every entity")
+	}
+	for i, rule := range s.Rules {
+		location := fmt.Sprintf("rules[%d]", i)
+		if len(rule.AttributeName) == 0 {
+			report.add(SeverityError, "segment", s.SegmentID, "", location+".attribute_name is missing")
+		}
+		if !slices.Contains(supportedOperators, rule.Operator) {
+			report.add(SeverityError, "segment", s.SegmentID, "", location+" has unknown operator "+strconv.Quote(rule.Operator))
+		}
+		if len(rule.Values) == 0 {
+			report.add(SeverityError, "segment", s.SegmentID, "", location+" has no values and never matches")
+		}
+		for j, value := range rule.Values {
+			str, isStr := value.(string)
+			if !isStr {
+				report.add(SeverityError, "segment", s.SegmentID, "", fmt.Sprintf("%s.values[%d] %v is not a string", location, j, value))
+				continue
+			}
+			if slices.Contains(numericOperators, rule.Operator) {
+				if _, err := strconv.ParseFloat(str, 64); err != nil {
+					report.add(SeverityError, "segment", s.SegmentID, "", fmt.Sprintf("%s.values[%d] %s is not a number, as required by the operator %s", location, j, strconv.Quote(str), rule.Operator))
+				}
+			}
+		}
+	}
+}
+
+// checkValue returns a description of the problem when value cannot be served as the given data type and format.
+func checkValue(value interface{}, dataType, format string) string {
+	if value == nil {
+		return "is missing"
+	}
+	switch dataType {
+	case "NUMERIC":
+		if !isNumber(value) {
+			return fmt.Sprintf("%v is not a number", value)
+		}
+	case "BOOLEAN":
+		if !isBool(value) {
+			return fmt.Sprintf("%v is not a boolean", value)
+		}
+	case "STRING":
+		switch format {
+		case "TEXT", "YAML":
+			if !isString(value) {
+				return fmt.Sprintf("%v is not a string", value)
+			}
+		case "JSON":
+		default:
+			return "has invalid format " + strconv.Quote(format)
+		}
+	}
+	return ""
+}
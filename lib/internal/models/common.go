@@ -21,15 +21,33 @@ import (
 	"errors"
 	"github.com/spaolacci/murmur3"
 	"math"
+	"sync/atomic"
+	"time"
 )
 
+// timeNow is the clock of the models package, see SetClock.
+var timeNow = time.Now
+
+// hashSeed is the murmur3 seed of the rollout and variant hashing. The default of 0 is shared with the other App Configuration SDKs.
+var hashSeed atomic.Uint32
+
+// SetHashSeed : Set the murmur3 seed of the rollout and variant hashing. Any seed but 0 reshuffles every rollout,
+// and the buckets no longer match the ones of the other App Configuration SDKs.
+func SetHashSeed(seed uint32) {
+	hashSeed.Store(seed)
+}
+
 func computeHash(str string) float64 {
-	seed := 0
-	hasher := murmur3.New32WithSeed(uint32(seed))
+	hasher := murmur3.New32WithSeed(hashSeed.Load())
 	hasher.Write([]byte(str))
 	return float64(hasher.Sum32())
 }
 
+// getNormalizedFraction : normalizes the murmur3 hash of str to a value between 0 (inclusive) and 1 (exclusive)
+func getNormalizedFraction(str string) float64 {
+	return computeHash(str) / math.Pow(2, 32)
+}
+
 func GetNormalizedValue(str string) int {
 	maxHashValue := math.Pow(2, 32)
 	normalizer := 100
@@ -17,10 +17,21 @@
 package models
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 	"github.com/sirupsen/logrus/hooks/test"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -620,3 +631,1131 @@ func TestExtractConfigurationsFromBootstrapJson(t *testing.T) {
 	}
 
 }
+
+func TestNestedAttributePaths(t *testing.T) {
+	type org struct {
+		Tier string `json:"tier"`
+		Name string
+	}
+	entityMap := map[string]interface{}{
+		"email": "alice@ibm.com",
+		"user": map[string]interface{}{
+			"org": map[string]interface{}{"tier": "gold"},
+		},
+		"device":  map[string]string{"os": "linux"},
+		"devices": []interface{}{map[string]interface{}{"os": "ios"}},
+		"account": &org{Tier: "silver", Name: "acme"},
+		"a/b":     map[string]interface{}{"c~d": 5},
+		"plan.id": "flat-key",
+	}
+
+	val, ok := getAttributeValue(entityMap, "email")
+	assert.True(t, ok)
+	assert.Equal(t, "alice@ibm.com", val)
+
+	val, ok = getAttributeValue(entityMap, "user.org.tier")
+	assert.True(t, ok)
+	assert.Equal(t, "gold", val)
+
+	val, ok = getAttributeValue(entityMap, "/user/org/tier")
+	assert.True(t, ok)
+	assert.Equal(t, "gold", val)
+
+	val, ok = getAttributeValue(entityMap, "device.os")
+	assert.True(t, ok)
+	assert.Equal(t, "linux", val)
+
+	val, ok = getAttributeValue(entityMap, "devices.0.os")
+	assert.True(t, ok)
+	assert.Equal(t, "ios", val)
+
+	val, ok = getAttributeValue(entityMap, "account.tier")
+	assert.True(t, ok)
+	assert.Equal(t, "silver", val)
+
+	val, ok = getAttributeValue(entityMap, "account.Name")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", val)
+
+	val, ok = getAttributeValue(entityMap, "/a~1b/c~0d")
+	assert.True(t, ok)
+	assert.Equal(t, 5, val)
+
+	// an exact key match takes precedence over path resolution
+	val, ok = getAttributeValue(entityMap, "plan.id")
+	assert.True(t, ok)
+	assert.Equal(t, "flat-key", val)
+
+	for _, missing := range []string{"phone", "user.org.size", "devices.1.os", "devices.x", "account.tier.x", "/user/missing"} {
+		_, ok = getAttributeValue(entityMap, missing)
+		assert.False(t, ok, missing)
+	}
+
+	nestedRule := Rule{
+		Operator:      "is",
+		AttributeName: "user.org.tier",
+		Values:        []interface{}{"gold"},
+	}
+	assert.True(t, nestedRule.EvaluateRule(entityMap))
+	nestedRule.AttributeName = "/account/tier"
+	assert.False(t, nestedRule.EvaluateRule(entityMap))
+}
+
+type testAccount struct {
+	Tier string `appconfig:"tier"`
+}
+
+type testEntity struct {
+	testAccount
+	Email   string            `appconfig:"email"`
+	Country string            `appconfig:"country,omitempty"`
+	Age     int               `appconfig:"age"`
+	Secret  string            `appconfig:"-"`
+	Org     map[string]string `appconfig:"org"`
+	Ignored string
+}
+
+type testProvider struct{}
+
+func (testProvider) EntityAttributes() map[string]interface{} {
+	return map[string]interface{}{"email": "bob@ibm.com"}
+}
+
+func TestEntityAttributesOf(t *testing.T) {
+	entity := testEntity{
+		testAccount: testAccount{Tier: "gold"},
+		Email:       "alice@ibm.com",
+		Age:         30,
+		Secret:      "s3cr3t",
+		Org:         map[string]string{"name": "acme"},
+		Ignored:     "x",
+	}
+	attributes, err := entityAttributesOf(&entity)
+	assert.Nil(t, err)
+	for name, expected := range map[string]interface{}{
+		"tier":  "gold",
+		"email": "alice@ibm.com",
+		"age":   30,
+		"org":   map[string]string{"name": "acme"},
+	} {
+		val, ok := attributes.lookup(name)
+		assert.True(t, ok)
+		assert.Equal(t, expected, val)
+	}
+	for _, name := range []string{"country", "Secret", "Ignored"} {
+		_, ok := attributes.lookup(name)
+		assert.False(t, ok)
+	}
+	assert.False(t, attributes.isEmpty())
+	// the fields are read in place, without building an attribute map
+	assert.Nil(t, attributes.values)
+	val, ok := resolveAttributeValue(attributes, "org.name", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", val)
+
+	// reflection metadata is cached per type
+	_, cached := taggedFieldsCache.Load(reflect.TypeOf(entity))
+	assert.True(t, cached)
+
+	attributes, err = entityAttributesOf(testProvider{})
+	assert.Nil(t, err)
+	val, _ = attributes.lookup("email")
+	assert.Equal(t, "bob@ibm.com", val)
+
+	attributes, err = entityAttributesOf(map[string]interface{}{"k": "v"})
+	assert.Nil(t, err)
+	val, _ = attributes.lookup("k")
+	assert.Equal(t, "v", val)
+
+	attributes, err = entityAttributesOf((*testEntity)(nil))
+	assert.Nil(t, err)
+	assert.True(t, attributes.isEmpty())
+
+	_, err = entityAttributesOf(42)
+	assert.NotNil(t, err)
+
+	// tagged struct fields can be traversed by nested attribute paths
+	val, ok = getAttributeValue(map[string]interface{}{"user": entity}, "user.tier")
+	assert.True(t, ok)
+	assert.Equal(t, "gold", val)
+
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{
+		"ibmers": {SegmentID: "ibmers", Rules: []Rule{{Operator: "endsWith", AttributeName: "email", Values: []interface{}{"ibm.com"}}}},
+	})
+	structFeature := Feature{
+		Name:          "f1",
+		FeatureID:     "f1",
+		DataType:      "STRING",
+		Format:        "TEXT",
+		EnabledValue:  "on",
+		DisabledValue: "off",
+		Enabled:       true,
+		SegmentRules:  []SegmentRule{{Order: 1, Value: "ibm", Rules: []RuleElem{{Segments: []string{"ibmers"}}}}},
+	}
+	assert.Equal(t, "ibm", structFeature.GetCurrentValueFor("e1", entity))
+	assert.Equal(t, "ibm", structFeature.GetCurrentValueFor("e1", testProvider{}))
+	assert.Equal(t, "on", structFeature.GetCurrentValueFor("e1", nil))
+	assert.Nil(t, structFeature.GetCurrentValueFor("e1", "not-an-entity"))
+
+	structProperty := Property{
+		Name:         "p1",
+		PropertyID:   "p1",
+		DataType:     "STRING",
+		Format:       "TEXT",
+		Value:        "default",
+		SegmentRules: []SegmentRule{{Order: 1, Value: "ibm", Rules: []RuleElem{{Segments: []string{"ibmers"}}}}},
+	}
+	assert.Equal(t, "ibm", structProperty.GetCurrentValueFor("e1", &entity))
+	assert.Equal(t, "default", structProperty.GetCurrentValueFor("e1", testEntity{Email: "eve@example.com"}))
+}
+
+func TestLazyAttributeResolvers(t *testing.T) {
+	mockLogger()
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{
+		"gold":   {SegmentID: "gold", Rules: []Rule{{Operator: "is", AttributeName: "tier", Values: []interface{}{"gold"}}}},
+		"silver": {SegmentID: "silver", Rules: []Rule{{Operator: "is", AttributeName: "tier", Values: []interface{}{"silver"}}}},
+		"ibmers": {SegmentID: "ibmers", Rules: []Rule{{Operator: "endsWith", AttributeName: "email", Values: []interface{}{"ibm.com"}}}},
+	})
+	lazyFeature := Feature{
+		Name:          "f1",
+		FeatureID:     "f1",
+		DataType:      "STRING",
+		Format:        "TEXT",
+		EnabledValue:  "on",
+		DisabledValue: "off",
+		Enabled:       true,
+		SegmentRules: []SegmentRule{
+			{Order: 1, Value: "silver", Rules: []RuleElem{{Segments: []string{"silver"}}}},
+			{Order: 2, Value: "gold", Rules: []RuleElem{{Segments: []string{"gold"}}}},
+		},
+	}
+
+	calls := 0
+	tierResolver := AttributeResolver(func(ctx context.Context) (interface{}, error) {
+		calls++
+		assert.Equal(t, "request-1", ctx.Value(testContextKey{}))
+		return "gold", nil
+	})
+	ctx := context.WithValue(context.Background(), testContextKey{}, "request-1")
+	entityMap := map[string]interface{}{"tier": tierResolver}
+	assert.Equal(t, "gold", lazyFeature.GetCurrentValueWithOptions("e1", EvaluationOptions{Context: ctx}, entityMap))
+	// memoized for the duration of one evaluation, even though two segments reference the attribute
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "gold", lazyFeature.GetCurrentValueWithOptions("e1", EvaluationOptions{Context: ctx}, entityMap))
+	assert.Equal(t, 2, calls)
+
+	// the resolver is not invoked when no rule references the attribute
+	lazyFeature.SegmentRules = []SegmentRule{{Order: 1, Value: "ibm", Rules: []RuleElem{{Segments: []string{"ibmers"}}}}}
+	entityMap["email"] = "alice@ibm.com"
+	assert.Equal(t, "ibm", lazyFeature.GetCurrentValueWithOptions("e1", EvaluationOptions{Context: ctx}, entityMap))
+	assert.Equal(t, 2, calls)
+
+	// plain functions and nested resolvers are supported, and failing resolvers make the rule fail
+	nestedRule := Rule{Operator: "is", AttributeName: "account.tier", Values: []interface{}{"gold"}}
+	assert.True(t, nestedRule.EvaluateRule(map[string]interface{}{
+		"account": func(ctx context.Context) (interface{}, error) {
+			return map[string]interface{}{"tier": "gold"}, nil
+		},
+	}))
+	assert.False(t, nestedRule.EvaluateRule(map[string]interface{}{
+		"account": AttributeResolver(func(ctx context.Context) (interface{}, error) {
+			return nil, errors.New("database unavailable")
+		}),
+	}))
+	assert.Equal(t, "AppConfiguration - Failed to resolve the entity attribute /account - database unavailable", hook.LastEntry().Message)
+}
+
+type testContextKey struct{}
+
+func TestValidate(t *testing.T) {
+	report := Validate([]byte(`invalidJsonStr`))
+	assert.False(t, report.IsValid())
+	assert.Equal(t, "configuration", report.Errors[0].Kind)
+
+	validConfig := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"NUMERIC","enabled_value":5,"disabled_value":0,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":40,"order":1,"rollout_percentage":"$default"}],"enabled":true,"rollout_percentage":50}],"properties":[{"name":"P1","property_id":"p1","type":"STRING","format":"TEXT","value":"v","segment_rules":[{"rules":[{"segments":["s1"]}],"value":"$default","order":1}]}]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"},{"values":["18"],"operator":"greaterThan","attribute_name":"age"}]}]}`
+	report = Validate([]byte(validConfig))
+	assert.True(t, report.IsValid())
+	assert.Empty(t, report.Errors)
+	assert.Empty(t, report.Warnings)
+
+	invalidConfig := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"NUMERIC","enabled_value":"five","disabled_value":0,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":40,"order":1,"rollout_percentage":"half"},{"rules":[{"segments":["ghost"]}],"value":40,"order":1}],"enabled":true,"rollout_percentage":150}],"properties":[{"name":"P1","property_id":"p1","type":"DATE","value":"v","segment_rules":[]}]}],"collections":[],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":[5],"operator":"is","attribute_name":"age"},{"values":["x"],"operator":"matches","attribute_name":"email"},{"values":["abc"],"operator":"lesserThan","attribute_name":"age"}]}]}`
+	report = Validate([]byte(invalidConfig))
+	assert.False(t, report.IsValid())
+	var messages []string
+	for _, issue := range report.Errors {
+		messages = append(messages, issue.String())
+	}
+	assert.ElementsMatch(t, []string{
+		"error: feature f1 (environment dev): enabled_value five is not a number",
+		"error: feature f1 (environment dev): rollout_percentage 150 is not between 0 and 100",
+		`error: feature f1 (environment dev): segment_rules[0].rollout_percentage "half" is not a number`,
+		"error: feature f1 (environment dev): segment_rules[1] has duplicate order 1, only one of the rules with this order is evaluated",
+		`error: feature f1 (environment dev): segment_rules[1] references unknown segment "ghost"`,
+		`error: property p1 (environment dev): invalid type "DATE"`,
+		"error: segment s1: rules[0].values[0] 5 is not a string",
+		`error: segment s1: rules[1] has unknown operator "matches"`,
+		`error: segment s1: rules[2].values[0] "abc" is not a number, as required by the operator lesserThan`,
+	}, messages)
+	assert.Contains(t, report.String(), "error: segment s1: rules[0].values[0] 5 is not a string")
+}
+
+func TestCaseInsensitiveOperators(t *testing.T) {
+	rule := Rule{Operator: "isIgnoreCase"}
+	assert.True(t, rule.operatorCheck("INDIA", "India"))
+	assert.True(t, rule.operatorCheck("straße", "STRASSE"))
+	assert.False(t, rule.operatorCheck("India", "Indiana"))
+	assert.True(t, rule.operatorCheck(1.5, "1.5"))
+
+	rule = Rule{Operator: "containsIgnoreCase"}
+	assert.True(t, rule.operatorCheck("Alice@IBM.com", "ibm"))
+	assert.False(t, rule.operatorCheck("Alice@IBM.com", "bob"))
+
+	rule = Rule{Operator: "startsWithIgnoreCase"}
+	assert.True(t, rule.operatorCheck("Alice@IBM.com", "ALICE"))
+	assert.False(t, rule.operatorCheck("Alice@IBM.com", "IBM"))
+
+	rule = Rule{Operator: "endsWithIgnoreCase"}
+	assert.True(t, rule.operatorCheck("Alice@IBM.com", "ibm.COM"))
+	assert.False(t, rule.operatorCheck("Alice@IBM.com", "alice"))
+
+	// the case-sensitive operators are unchanged
+	rule = Rule{Operator: "is"}
+	assert.False(t, rule.operatorCheck("INDIA", "India"))
+
+	countryRule := Rule{Operator: "isIgnoreCase", AttributeName: "country", Values: []interface{}{"India"}}
+	assert.True(t, countryRule.EvaluateRule(map[string]interface{}{"country": "india"}))
+
+	// NFC normalization makes composed and decomposed forms match
+	rule = Rule{Operator: "is"}
+	assert.False(t, rule.operatorCheck("Caf\u00e9", "Cafe\u0301"))
+	SetUnicodeNormalization(true)
+	defer SetUnicodeNormalization(false)
+	assert.True(t, rule.operatorCheck("Caf\u00e9", "Cafe\u0301"))
+	rule = Rule{Operator: "endsWithIgnoreCase"}
+	assert.True(t, rule.operatorCheck("CAF\u00c9", "e\u0301"))
+
+	report := Validate([]byte(`{"environments":[],"collections":[],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["india"],"operator":"isIgnoreCase","attribute_name":"country"}]}]}`))
+	assert.True(t, report.IsValid())
+}
+
+func TestBucketingAttribute(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{})
+	bucketedFeature := Feature{
+		Name:               "f1",
+		FeatureID:          "f1",
+		DataType:           "BOOLEAN",
+		EnabledValue:       true,
+		DisabledValue:      false,
+		Enabled:            true,
+		RolloutPercentage:  Int(50),
+		BucketingAttribute: "account_id",
+	}
+	assert.Equal(t, "account_id", bucketedFeature.GetBucketingAttribute())
+
+	// every entity of the tenant gets the same value
+	expected := GetNormalizedValue("acme:f1") < 50
+	for i := 0; i < 20; i++ {
+		entityID := "user" + strconv.Itoa(i)
+		assert.Equal(t, expected, bucketedFeature.GetCurrentValue(entityID, map[string]interface{}{"account_id": "acme"}))
+		// without the attribute, the entity ID is used
+		assert.Equal(t, GetNormalizedValue(entityID+":f1") < 50, bucketedFeature.GetCurrentValue(entityID, map[string]interface{}{}))
+		assert.Equal(t, GetNormalizedValue(entityID+":f1") < 50, bucketedFeature.GetCurrentValue(entityID))
+	}
+
+	// the bucketing attribute of the call takes precedence over the one of the feature, and may be a nested path
+	options := EvaluationOptions{BucketingAttribute: "org.id"}
+	attributes := map[string]interface{}{"account_id": "acme", "org": map[string]interface{}{"id": 42}}
+	assert.Equal(t, GetNormalizedValue("42:f1") < 50, bucketedFeature.GetCurrentValueWithOptions("user1", options, attributes))
+
+	// the bucketing attribute also applies to the segment level rollouts
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{
+		"all": {SegmentID: "all", Rules: []Rule{{Operator: "is", AttributeName: "account_id", Values: []interface{}{"acme"}}}},
+	})
+	bucketedFeature.SegmentRules = []SegmentRule{{Order: 1, Value: "$default", RolloutPercentage: Interface(50.0), Rules: []RuleElem{{Segments: []string{"all"}}}}}
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, bucketedFeature.GetCurrentValue("user"+strconv.Itoa(i), map[string]interface{}{"account_id": "acme"}))
+	}
+}
+
+func TestMultivariateFeature(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{
+		"beta": {SegmentID: "beta", Rules: []Rule{{Operator: "is", AttributeName: "beta", Values: []interface{}{"true"}}}},
+	})
+	abFeature := Feature{
+		Name:          "checkout",
+		FeatureID:     "checkout",
+		DataType:      "STRING",
+		Format:        "TEXT",
+		EnabledValue:  "classic",
+		DisabledValue: "off",
+		Enabled:       true,
+		Variants: []Variant{
+			{Key: "a", Value: "classic", Weight: 50},
+			{Key: "b", Value: "one-page", Weight: 30},
+			{Key: "c", Value: "express", Weight: 20},
+		},
+		SegmentRules: []SegmentRule{{Order: 1, Value: "beta-checkout", Rules: []RuleElem{{Segments: []string{"beta"}}}}},
+	}
+	assert.Equal(t, 3, len(abFeature.GetVariants()))
+
+	counts := make(map[string]int)
+	values := map[string]string{"a": "classic", "b": "one-page", "c": "express"}
+	for i := 0; i < 10000; i++ {
+		entityID := "user" + strconv.Itoa(i)
+		details := abFeature.GetEvaluationDetails(entityID, EvaluationOptions{})
+		assert.Equal(t, ReasonRolloutIncluded, details.Reason)
+		assert.True(t, details.Enabled)
+		assert.Equal(t, values[details.VariantKey], details.Value)
+		// the assignment is deterministic
+		assert.Equal(t, details.Value, abFeature.GetCurrentValue(entityID))
+		counts[details.VariantKey]++
+	}
+	assert.InDelta(t, 5000, counts["a"], 250)
+	assert.InDelta(t, 3000, counts["b"], 250)
+	assert.InDelta(t, 2000, counts["c"], 250)
+
+	// segment override values take precedence over the variants
+	details := abFeature.GetEvaluationDetails("user1", EvaluationOptions{}, map[string]interface{}{"beta": "true"})
+	assert.Equal(t, "beta-checkout", details.Value)
+	assert.Equal(t, "beta", details.SegmentID)
+	assert.Equal(t, "", details.VariantKey)
+	assert.Equal(t, ReasonSegmentMatch, details.Reason)
+
+	// entities excluded by the rollout get the disabled value and no variant
+	abFeature.RolloutPercentage = Int(0)
+	details = abFeature.GetEvaluationDetails("user1", EvaluationOptions{})
+	assert.Equal(t, "off", details.Value)
+	assert.Equal(t, "", details.VariantKey)
+	assert.Equal(t, ReasonRolloutExcluded, details.Reason)
+
+	abFeature.Enabled = false
+	assert.Equal(t, ReasonFeatureDisabled, abFeature.GetEvaluationDetails("user1", EvaluationOptions{}).Reason)
+
+	details = abFeature.GetEvaluationDetails("", EvaluationOptions{})
+	assert.Equal(t, ReasonError, details.Reason)
+	assert.Nil(t, details.Value)
+
+	report := Validate([]byte(`{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"NUMERIC","enabled_value":1,"disabled_value":0,"variants":[{"key":"a","value":1,"weight":50},{"key":"a","value":"two","weight":-1}],"segment_rules":[],"enabled":true}],"properties":[]}],"collections":[],"segments":[]}`))
+	assert.Equal(t, 3, len(report.Errors))
+}
+
+func TestStickyAssignments(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{})
+	store := NewInMemoryAssignmentStore()
+	SetAssignmentStore(store)
+	defer SetAssignmentStore(nil)
+	abFeature := Feature{
+		Name:          "checkout",
+		FeatureID:     "checkout",
+		DataType:      "STRING",
+		Format:        "TEXT",
+		EnabledValue:  "classic",
+		DisabledValue: "off",
+		Enabled:       true,
+		Variants: []Variant{
+			{Key: "a", Value: "classic", Weight: 50},
+			{Key: "b", Value: "one-page", Weight: 50},
+		},
+	}
+	details := abFeature.GetEvaluationDetails("user1", EvaluationOptions{})
+	assert.Equal(t, ReasonRolloutIncluded, details.Reason)
+	assignment, ok, err := store.Get("checkout", "user1")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, details.VariantKey, assignment.VariantKey)
+
+	// the entity keeps its variant when the weights and the rollout change
+	abFeature.Variants = []Variant{{Key: "c", Value: "express", Weight: 100}}
+	abFeature.RolloutPercentage = Int(0)
+	sticky := abFeature.GetEvaluationDetails("user1", EvaluationOptions{})
+	assert.Equal(t, ReasonStickyAssignment, sticky.Reason)
+	assert.Equal(t, details.Value, sticky.Value)
+	assert.Equal(t, details.VariantKey, sticky.VariantKey)
+
+	// entities excluded by the rollout are not stored
+	assert.Equal(t, "off", abFeature.GetCurrentValue("user2"))
+	_, ok, _ = store.Get("checkout", "user2")
+	assert.False(t, ok)
+
+	// a disabled feature flag ignores the assignments
+	abFeature.Enabled = false
+	assert.Equal(t, "off", abFeature.GetCurrentValue("user1"))
+	abFeature.Enabled = true
+
+	assert.Nil(t, store.Reset("checkout", "user1"))
+	assert.Equal(t, ReasonRolloutExcluded, abFeature.GetEvaluationDetails("user1", EvaluationOptions{}).Reason)
+
+	// the file store survives a restart
+	path := filepath.Join(t.TempDir(), "assignments.json")
+	fileStore, err := NewFileAssignmentStore(path)
+	assert.Nil(t, err)
+	SetAssignmentStore(fileStore)
+	abFeature.RolloutPercentage = Int(100)
+	assert.Equal(t, "express", abFeature.GetCurrentValue("user3"))
+	// the assignments are written in the background
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, 3*assignmentFlushDelay, 10*time.Millisecond)
+	reopened, err := NewFileAssignmentStore(path)
+	assert.Nil(t, err)
+	assignment, ok, _ = reopened.Get("checkout", "user3")
+	assert.True(t, ok)
+	assert.Equal(t, "express", assignment.Value)
+	assert.Equal(t, "c", assignment.VariantKey)
+	assert.Nil(t, reopened.Reset("checkout", ""))
+	assert.Nil(t, reopened.Flush())
+	assert.Nil(t, reopened.Flush())
+	reopened, _ = NewFileAssignmentStore(path)
+	_, ok, _ = reopened.Get("checkout", "user3")
+	assert.False(t, ok)
+
+	assert.Nil(t, os.WriteFile(path, []byte("{"), 0600))
+	_, err = NewFileAssignmentStore(path)
+	assert.NotNil(t, err)
+
+	// concurrent changes are all written, none is lost to a reordered rename
+	path = filepath.Join(t.TempDir(), "assignments.json")
+	fileStore, _ = NewFileAssignmentStore(path)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fileStore.Set("checkout", "user"+strconv.Itoa(i), Assignment{Value: "express"})
+			fileStore.Flush()
+		}(i)
+	}
+	wg.Wait()
+	reopened, _ = NewFileAssignmentStore(path)
+	for i := 0; i < 20; i++ {
+		_, ok, _ = reopened.Get("checkout", "user"+strconv.Itoa(i))
+		assert.True(t, ok)
+	}
+	SetAssignmentStore(nil)
+}
+
+func TestExposureEvents(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	defer SetClock(nil)
+	events := make(chan ExposureEvent, 10)
+	SetExposureSink(NewChannelExposureSink(events), time.Hour)
+	defer SetExposureSink(nil, 0)
+	feature := Feature{
+		Name:          "checkout",
+		FeatureID:     "checkout",
+		DataType:      "NUMERIC",
+		EnabledValue:  float64(2),
+		DisabledValue: float64(1),
+		Enabled:       true,
+	}
+	assert.Equal(t, float64(2), feature.GetCurrentValue("user1"))
+	event := <-events
+	assert.Equal(t, ExposureEvent{FeatureID: "checkout", EntityID: "user1", Value: float64(2), Enabled: true, Reason: ReasonRolloutIncluded, Timestamp: now}, event)
+
+	// repeated exposures within the window are skipped, unless the served value changes
+	feature.GetCurrentValue("user1")
+	assert.Equal(t, 0, len(events))
+	feature.GetCurrentValue("user2")
+	assert.Equal(t, "user2", (<-events).EntityID)
+	feature.Enabled = false
+	feature.GetCurrentValue("user1")
+	assert.Equal(t, ReasonFeatureDisabled, (<-events).Reason)
+	feature.GetCurrentValue("user1")
+	assert.Equal(t, 0, len(events))
+	now = now.Add(time.Hour)
+	feature.GetCurrentValue("user1")
+	assert.Equal(t, 1, len(events))
+
+	// failed evaluations are not exposures
+	feature.GetCurrentValue("")
+	assert.Equal(t, 1, len(events))
+
+	path := filepath.Join(t.TempDir(), "exposures.jsonl")
+	sink, err := NewJSONLExposureSink(path)
+	assert.Nil(t, err)
+	SetExposureSink(sink, 0)
+	feature.GetCurrentValue("user1")
+	feature.GetCurrentValue("user1")
+	assert.Nil(t, sink.Close())
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"feature_id":"checkout","entity_id":"user1","value":1,"enabled":false,"reason":"FEATURE_DISABLED","timestamp":"2026-01-01T01:00:00Z"}`+"\n", string(data[:len(data)/2]))
+	assert.NotNil(t, sink.Emit(ExposureEvent{FeatureID: "checkout", EntityID: "user1"}))
+	assert.Nil(t, sink.Close())
+
+	// the events dropped by a full channel are counted, and logged once per interval rather than once per event
+	mockLogger()
+	hook.Reset()
+	full := NewChannelExposureSink(make(chan ExposureEvent))
+	SetExposureSink(full, 0)
+	for i := 0; i < 5; i++ {
+		feature.GetCurrentValue("user1")
+	}
+	assert.Equal(t, int64(5), full.Dropped())
+	assert.Equal(t, 1, len(hook.AllEntries()))
+	assert.Equal(t, "AppConfiguration - Failed to emit the exposure event: the exposure channel is full, dropping the exposure of checkout to user1 (1 failed exposure events)", hook.LastEntry().Message)
+}
+
+func TestFeaturePrerequisites(t *testing.T) {
+	payments := Feature{
+		Name:          "payments-v2",
+		FeatureID:     "payments-v2",
+		DataType:      "BOOLEAN",
+		EnabledValue:  true,
+		DisabledValue: false,
+		Enabled:       true,
+		SegmentRules:  []SegmentRule{{Order: 1, Value: false, Rules: []RuleElem{{Segments: []string{"legacy"}}}}},
+	}
+	checkout := Feature{
+		Name:          "new-checkout",
+		FeatureID:     "new-checkout",
+		DataType:      "STRING",
+		Format:        "TEXT",
+		EnabledValue:  "new",
+		DisabledValue: "old",
+		Enabled:       true,
+		Prerequisites: []Prerequisite{{FeatureID: "payments-v2", Value: true}},
+	}
+	SetCache(map[string]Feature{"payments-v2": payments, "new-checkout": checkout}, map[string]Property{}, map[string]Segment{
+		"legacy": {SegmentID: "legacy", Rules: []Rule{{Operator: "is", AttributeName: "plan", Values: []interface{}{"legacy"}}}},
+	})
+	assert.Equal(t, 1, len(checkout.GetPrerequisites()))
+
+	details := checkout.GetEvaluationDetails("user1", EvaluationOptions{}, map[string]interface{}{"plan": "pro"})
+	assert.Equal(t, "new", details.Value)
+	assert.Equal(t, ReasonRolloutIncluded, details.Reason)
+
+	// the prerequisite is evaluated without side effects, only the requested feature flag is assigned
+	store := NewInMemoryAssignmentStore()
+	SetAssignmentStore(store)
+	checkout.GetEvaluationDetails("user2", EvaluationOptions{}, map[string]interface{}{"plan": "pro"})
+	SetAssignmentStore(nil)
+	_, ok, _ := store.Get("new-checkout", "user2")
+	assert.True(t, ok)
+	_, ok, _ = store.Get("payments-v2", "user2")
+	assert.False(t, ok)
+
+	// the prerequisite is evaluated for the same entity
+	details = checkout.GetEvaluationDetails("user1", EvaluationOptions{}, map[string]interface{}{"plan": "legacy"})
+	assert.Equal(t, "old", details.Value)
+	assert.False(t, details.Enabled)
+	assert.Equal(t, ReasonPrerequisiteFailed, details.Reason)
+	assert.Equal(t, "payments-v2", details.FailedPrerequisite)
+
+	// unknown prerequisites are not met
+	checkout.Prerequisites = []Prerequisite{{FeatureID: "missing", Value: true}}
+	assert.Equal(t, "missing", checkout.GetEvaluationDetails("user1", EvaluationOptions{}).FailedPrerequisite)
+
+	// cycles are not met, rather than recursing forever
+	payments.Prerequisites = []Prerequisite{{FeatureID: "new-checkout", Value: "new"}}
+	checkout.Prerequisites = []Prerequisite{{FeatureID: "payments-v2", Value: true}}
+	SetCache(map[string]Feature{"payments-v2": payments, "new-checkout": checkout}, map[string]Property{}, map[string]Segment{})
+	details = checkout.GetEvaluationDetails("user1", EvaluationOptions{})
+	assert.Equal(t, ReasonPrerequisiteFailed, details.Reason)
+	assert.Equal(t, "payments-v2", details.FailedPrerequisite)
+
+	report := Validate([]byte(`{"environments":[{"name":"Dev","environment_id":"dev","features":[
+		{"name":"A","feature_id":"a","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true,"prerequisites":[{"feature_id":"b","value":true}]},
+		{"name":"B","feature_id":"b","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true,"prerequisites":[{"feature_id":"c","value":true},{"feature_id":"missing","value":true}]},
+		{"name":"C","feature_id":"c","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true,"prerequisites":[{"feature_id":"a","value":"yes"}]}
+	],"properties":[]}],"collections":[],"segments":[]}`))
+	assert.Equal(t, 3, len(report.Errors))
+	assert.Contains(t, report.String(), "cyclic prerequisites a -> b -> c -> a")
+	assert.Contains(t, report.String(), `unknown feature "missing"`)
+}
+
+func TestSimulate(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{})
+	baseline := []byte(`{"environments":[{"name":"Dev","environment_id":"dev","features":[
+		{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"enabled":true,"rollout_percentage":10,
+			"segment_rules":[{"rules":[{"segments":["india"]}],"value":"$default","order":1,"rollout_percentage":100}]}
+	],"properties":[]}],"collections":[{"name":"C","collection_id":"c"}],"segments":[
+		{"name":"India","segment_id":"india","rules":[{"attribute_name":"country","operator":"is","values":["IN"]}]}
+	]}`)
+	compare := bytes.Replace(baseline, []byte(`"rollout_percentage":10`), []byte(`"rollout_percentage":25`), 1)
+
+	entities, err := ReadSimulationEntities(strings.NewReader("entity_id,country\nuser0,IN\nuser1,\n"), "csv")
+	assert.Nil(t, err)
+	assert.Equal(t, []SimulationEntity{{EntityID: "user0", Attributes: map[string]interface{}{"country": "IN"}}, {EntityID: "user1", Attributes: map[string]interface{}{}}}, entities)
+	for i := 2; i < 1000; i++ {
+		entities = append(entities, SimulationEntity{EntityID: "user" + strconv.Itoa(i)})
+	}
+
+	report, err := Simulate(baseline, compare, "dev", "c", entities)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, report.Entities)
+	assert.Equal(t, 1, len(report.Features))
+	simulation := report.Features[0]
+	assert.Equal(t, 1000, simulation.Distribution["true"]+simulation.Distribution["false"])
+	assert.InDelta(t, 100, simulation.Distribution["true"], 40)
+	assert.InDelta(t, 250, simulation.CompareDistribution["true"], 40)
+	// raising the rollout only turns the feature flag on, and never for the entities of the 100% segment
+	assert.Equal(t, simulation.CompareDistribution["true"]-simulation.Distribution["true"], len(simulation.Changes))
+	for _, change := range simulation.Changes {
+		assert.Equal(t, false, change.Before)
+		assert.Equal(t, true, change.After)
+		assert.NotEqual(t, "user0", change.EntityID)
+		feature := Feature{Name: "F1", FeatureID: "f1", DataType: "BOOLEAN", EnabledValue: true, DisabledValue: false, Enabled: true, RolloutPercentage: Int(25)}
+		assert.Equal(t, true, feature.GetCurrentValue(change.EntityID))
+	}
+
+	// the simulation does not touch the SDK cache
+	assert.Equal(t, 0, len(GetCacheInstance().FeatureMap))
+
+	entities, err = ReadSimulationEntities(strings.NewReader(`{"entity_id":"user0","attributes":{"country":"IN"}}`+"\n\n"+`{"entity_id":"user1"}`), "jsonl")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entities))
+	report, err = Simulate(baseline, nil, "dev", "c", entities)
+	assert.Nil(t, err)
+	assert.Nil(t, report.Features[0].CompareDistribution)
+	assert.Equal(t, 1, report.Features[0].Distribution["true"])
+
+	_, err = ReadSimulationEntities(strings.NewReader("name\nuser0\n"), "csv")
+	assert.NotNil(t, err)
+	_, err = ReadSimulationEntities(strings.NewReader(""), "xml")
+	assert.NotNil(t, err)
+	_, err = Simulate(baseline, nil, "prod", "c", entities)
+	assert.NotNil(t, err)
+}
+
+func TestHashSaltAndSeed(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{})
+	feature := Feature{
+		Name:              "f1",
+		FeatureID:         "f1",
+		DataType:          "BOOLEAN",
+		EnabledValue:      true,
+		DisabledValue:     false,
+		Enabled:           true,
+		RolloutPercentage: Int(50),
+	}
+	unsalted := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		entityID := "user" + strconv.Itoa(i)
+		unsalted[entityID] = feature.GetCurrentValue(entityID).(bool)
+		// the default bucketing is unchanged
+		assert.Equal(t, GetNormalizedValue(entityID+":f1") < 50, unsalted[entityID])
+	}
+
+	feature.HashSalt = "2026-q1"
+	assert.Equal(t, "2026-q1", feature.GetHashSalt())
+	agreements := 0
+	for i := 0; i < 1000; i++ {
+		entityID := "user" + strconv.Itoa(i)
+		salted := feature.GetCurrentValue(entityID).(bool)
+		assert.Equal(t, GetNormalizedValue(entityID+":f1:2026-q1") < 50, salted)
+		if salted == unsalted[entityID] {
+			agreements++
+		}
+	}
+	// the salted cohort is independent of the unsalted one
+	assert.InDelta(t, 500, agreements, 80)
+
+	feature.HashSalt = ""
+	SetHashSeed(7)
+	defer SetHashSeed(0)
+	agreements = 0
+	for i := 0; i < 1000; i++ {
+		entityID := "user" + strconv.Itoa(i)
+		if feature.GetCurrentValue(entityID).(bool) == unsalted[entityID] {
+			agreements++
+		}
+	}
+	assert.InDelta(t, 500, agreements, 80)
+	SetHashSeed(0)
+	assert.Equal(t, unsalted["user1"], feature.GetCurrentValue("user1"))
+}
+
+func TestFeatureSchedule(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{})
+	clock := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return clock })
+	defer SetClock(nil)
+	activeFrom := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	activeUntil := activeFrom.Add(7 * 24 * time.Hour)
+	feature := Feature{
+		Name:          "f1",
+		FeatureID:     "f1",
+		DataType:      "BOOLEAN",
+		EnabledValue:  true,
+		DisabledValue: false,
+		Enabled:       true,
+		Schedule:      &Schedule{ActiveFrom: &activeFrom, ActiveUntil: &activeUntil},
+	}
+	assert.Equal(t, ReasonScheduleInactive, feature.GetEvaluationDetails("user1", EvaluationOptions{}).Reason)
+	clock = activeFrom
+	assert.Equal(t, true, feature.GetCurrentValue("user1"))
+	clock = activeUntil
+	assert.Equal(t, false, feature.GetCurrentValue("user1"))
+
+	// the rollout ramps from 5% to 100% over 48 hours
+	feature.Schedule = &Schedule{Ramp: &Ramp{Start: activeFrom, End: activeFrom.Add(48 * time.Hour), FromPercentage: 5, ToPercentage: 100}}
+	rolledOut := func() int {
+		count := 0
+		for i := 0; i < 1000; i++ {
+			if feature.GetCurrentValue("user" + strconv.Itoa(i)).(bool) {
+				count++
+			}
+		}
+		return count
+	}
+	clock = activeFrom.Add(-time.Hour)
+	assert.InDelta(t, 50, rolledOut(), 25)
+	clock = activeFrom.Add(24 * time.Hour)
+	assert.Equal(t, 52, feature.Schedule.Ramp.percentageAt(clock))
+	assert.InDelta(t, 520, rolledOut(), 50)
+	clock = activeFrom.Add(72 * time.Hour)
+	assert.Equal(t, 1000, rolledOut())
+
+	report := Validate([]byte(`{"environments":[{"name":"Dev","environment_id":"dev","features":[
+		{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true,
+			"schedule":{"active_from":"2026-03-09T09:00:00Z","active_until":"2026-03-02T09:00:00Z","ramp":{"start":"2026-03-02T09:00:00Z","end":"2026-03-04T09:00:00Z","from_percentage":5,"to_percentage":120}}}
+	],"properties":[]}],"collections":[],"segments":[]}`))
+	assert.Equal(t, 2, len(report.Errors))
+}
+
+func TestGetSegmentsForEntity(t *testing.T) {
+	segments := map[string]Segment{
+		"adults": {SegmentID: "adults", Name: "Adults", Rules: []Rule{
+			{Operator: "greaterThanEquals", AttributeName: "age", Values: []interface{}{"18"}},
+			{Operator: "isNot", AttributeName: "country", Values: []interface{}{"US", "CA"}},
+		}},
+		"ibmers": {SegmentID: "ibmers", Name: "IBMers", Rules: []Rule{
+			{Operator: "endsWith", AttributeName: "email", Values: []interface{}{"@ibm.com", "@in.ibm.com"}},
+		}},
+	}
+	memberships := GetSegmentsForEntity(segments, "user1", map[string]interface{}{"age": 32, "country": "CA", "email": 7})
+	assert.Equal(t, 2, len(memberships))
+
+	adults := memberships[0]
+	assert.Equal(t, "adults", adults.SegmentID)
+	assert.Equal(t, "Adults", adults.Name)
+	assert.False(t, adults.Matched)
+	assert.Equal(t, RuleResult{AttributeName: "age", Operator: "greaterThanEquals", Values: []interface{}{"18"}, AttributeValue: 32, AttributePresent: true, Passed: true, Reason: "32 greaterThanEquals 18"}, adults.Rules[0])
+	assert.False(t, adults.Rules[1].Passed)
+	assert.Equal(t, "CA fails isNot CA", adults.Rules[1].Reason)
+
+	// values that cannot be compared are reported as mismatches
+	ibmers := memberships[1]
+	assert.False(t, ibmers.Matched)
+	assert.Equal(t, "7 does not satisfy endsWith for any of the values", ibmers.Rules[0].Reason)
+
+	memberships = GetSegmentsForEntity(segments, "user1", map[string]interface{}{"age": "40", "country": "IN", "email": "bob@in.ibm.com"})
+	assert.True(t, memberships[0].Matched)
+	assert.Equal(t, "IN isNot each of the values", memberships[0].Rules[1].Reason)
+	assert.True(t, memberships[1].Matched)
+	assert.Equal(t, "bob@in.ibm.com endsWith @in.ibm.com", memberships[1].Rules[0].Reason)
+
+	memberships = GetSegmentsForEntity(segments, "user1", nil)
+	assert.False(t, memberships[0].Rules[0].AttributePresent)
+	assert.Equal(t, "the attribute is not present in the entity attributes", memberships[0].Rules[0].Reason)
+}
+
+func TestExplain(t *testing.T) {
+	SetCache(map[string]Feature{}, map[string]Property{}, map[string]Segment{
+		"beta":   {SegmentID: "beta", Name: "Beta", Rules: []Rule{{Operator: "is", AttributeName: "beta", Values: []interface{}{"true"}}}},
+		"ibmers": {SegmentID: "ibmers", Name: "IBMers", Rules: []Rule{{Operator: "endsWith", AttributeName: "email", Values: []interface{}{"@ibm.com"}}}},
+	})
+	feature := Feature{
+		Name:              "f1",
+		FeatureID:         "f1",
+		DataType:          "STRING",
+		Format:            "TEXT",
+		EnabledValue:      "on",
+		DisabledValue:     "off",
+		Enabled:           true,
+		RolloutPercentage: Int(50),
+		SegmentRules: []SegmentRule{
+			{Order: 2, Value: "$default", RolloutPercentage: Interface(30.0), Rules: []RuleElem{{Segments: []string{"ibmers"}}}},
+			{Order: 1, Value: "beta-value", RolloutPercentage: Interface("$default"), Rules: []RuleElem{{Segments: []string{"missing", "beta"}}}},
+		},
+	}
+	attributes := map[string]interface{}{"beta": "false", "email": "alice@ibm.com"}
+	trace := feature.Explain("user1", attributes)
+	assert.Equal(t, feature.GetEvaluationDetails("user1", EvaluationOptions{}, attributes), trace.Result)
+	assert.Equal(t, 2, len(trace.SegmentRules))
+	assert.Equal(t, 1, trace.SegmentRules[0].Order)
+	assert.Equal(t, []SegmentMembership{{SegmentID: "missing"}, {SegmentID: "beta", Name: "Beta", Rules: []RuleResult{{AttributeName: "beta", Operator: "is", Values: []interface{}{"true"}, AttributeValue: "false", AttributePresent: true, Reason: "false does not satisfy is for any of the values"}}}}, trace.SegmentRules[0].Segments)
+	assert.Nil(t, trace.SegmentRules[0].Rollout)
+	rollout := trace.SegmentRules[1].Rollout
+	assert.Equal(t, RolloutTrace{HashKey: "user1:f1", Bucket: GetNormalizedValue("user1:f1"), Percentage: 30, Included: GetNormalizedValue("user1:f1") < 30}, *rollout)
+	assert.Nil(t, trace.Rollout)
+
+	text := trace.String()
+	assert.Contains(t, text, "segment rule 1: value \"beta-value\", rollout \"$default\"")
+	assert.Contains(t, text, "segment missing (): not matched\n      the segment does not exist")
+	assert.Contains(t, text, "[fail] beta is [\"true\"]: false does not satisfy is for any of the values")
+	assert.Contains(t, text, "[pass] email endsWith [\"@ibm.com\"]: alice@ibm.com endsWith @ibm.com")
+	assert.Contains(t, text, rollout.String())
+	assert.Contains(t, text, "result: "+traceValue(trace.Result.Value)+" ("+trace.Result.Reason+"), segment ibmers")
+
+	var decoded EvaluationTrace
+	assert.Nil(t, json.Unmarshal([]byte(trace.JSON()), &decoded))
+	assert.Equal(t, "f1", decoded.FeatureID)
+
+	// without a matching segment, the feature level rollout is traced
+	trace = feature.Explain("user1", nil)
+	assert.Equal(t, []string{"the segment rules are skipped, the entity has no attributes"}, trace.Notes)
+	assert.Equal(t, 50, trace.Rollout.Percentage)
+
+	feature.Enabled = false
+	trace = feature.Explain("user1", attributes)
+	assert.Equal(t, ReasonFeatureDisabled, trace.Result.Reason)
+	assert.Equal(t, 0, len(trace.SegmentRules))
+	assert.Contains(t, trace.String(), `result: "off" (FEATURE_DISABLED)`)
+	assert.Equal(t, ReasonError, feature.Explain("", nil).Result.Reason)
+}
+
+func TestSnapshot(t *testing.T) {
+	config := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F2","feature_id":"f2","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":false,"order":1}],"enabled":true},{"name":"F1","feature_id":"f1","type":"STRING","format":"TEXT","enabled_value":"on","disabled_value":"off","segment_rules":[],"enabled":false}],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":5,"segment_rules":[]}]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	cache, err := newCacheFromConfig([]byte(config), "dev", "c1")
+	assert.Nil(t, err)
+
+	_, err = ExportSnapshot(nil, "dev", "c1")
+	assert.EqualError(t, err, "no configurations are loaded")
+
+	exportedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return exportedAt })
+	defer SetClock(nil)
+	cache.Source, cache.FetchedAt = DataSourceService, exportedAt.Add(-time.Hour)
+	data, err := ExportSnapshot(cache, "dev", "c1")
+	assert.Nil(t, err)
+
+	var snapshot Snapshot
+	assert.Nil(t, json.Unmarshal(data, &snapshot))
+	assert.Equal(t, "dev", snapshot.Metadata.EnvironmentID)
+	assert.Equal(t, "c1", snapshot.Metadata.CollectionID)
+	assert.Equal(t, DataSourceService, snapshot.Metadata.Source)
+	assert.Equal(t, exportedAt.Add(-time.Hour), *snapshot.Metadata.FetchedAt)
+	assert.Equal(t, exportedAt, snapshot.Metadata.ExportedAt)
+	// the features are sorted by ID
+	assert.Equal(t, "f1", snapshot.Environments[0].Features[0].GetFeatureID())
+	assert.Equal(t, "f2", snapshot.Environments[0].Features[1].GetFeatureID())
+
+	// the snapshot is read back for its own context
+	configurations, metadata, err := ReadSnapshot(data, "prod", "c2")
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot.Metadata, metadata)
+	replayed, err := newCacheFromConfig(data, "dev", "c1")
+	assert.Nil(t, err)
+	assert.Equal(t, cache.FeatureMap, replayed.FeatureMap)
+	assert.Equal(t, cache.PropertyMap, replayed.PropertyMap)
+	assert.Equal(t, cache.SegmentMap, replayed.SegmentMap)
+	extracted, _ := ExtractConfigurations(data, "dev", "c1")
+	assert.JSONEq(t, string(extracted), string(configurations))
+
+	// and a bootstrap file for the given context
+	_, metadata, err = ReadSnapshot([]byte(config), "dev", "c1")
+	assert.Nil(t, err)
+	assert.Equal(t, SnapshotMetadata{EnvironmentID: "dev", CollectionID: "c1"}, metadata)
+	_, _, err = ReadSnapshot([]byte(config), "prod", "c1")
+	assert.EqualError(t, err, "no data matching for environment id: prod")
+	_, _, err = ReadSnapshot([]byte("{"), "dev", "c1")
+	assert.EqualError(t, err, "failed to parse snapshot: unexpected end of JSON input")
+}
+
+func TestHistory(t *testing.T) {
+	configuration := func(value string) *Cache {
+		return &Cache{
+			FeatureMap: map[string]Feature{"f1": {Name: "F1", FeatureID: "f1", DataType: "STRING", Format: "TEXT", EnabledValue: value, DisabledValue: "off", Enabled: true}},
+			Source:     DataSourceService,
+		}
+	}
+	history := NewHistory(2)
+	_, ok := history.Latest()
+	assert.False(t, ok)
+
+	v1, recorded, err := history.Record(configuration("a"), "dev", "c1")
+	assert.Nil(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, 1, v1.Version)
+	assert.Equal(t, DataSourceService, v1.Source)
+
+	// unchanged configurations are not recorded again
+	same, recorded, _ := history.Record(configuration("a"), "dev", "c1")
+	assert.False(t, recorded)
+	assert.Equal(t, v1.Version, same.Version)
+
+	history.Record(configuration("b"), "dev", "c1")
+	v3, _, _ := history.Record(configuration("c"), "dev", "c1")
+	assert.Equal(t, 3, v3.Version)
+
+	// only the last versions are kept
+	versions := history.Versions()
+	assert.Equal(t, 2, len(versions))
+	assert.Equal(t, 2, versions[0].Version)
+	_, ok = history.Get(1)
+	assert.False(t, ok)
+	v2, ok := history.Get(2)
+	assert.True(t, ok)
+	cache, err := newCacheFromConfig(v2.Snapshot, "dev", "c1")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", cache.FeatureMap["f1"].EnabledValue)
+	latest, _ := history.Latest()
+	assert.Equal(t, 3, latest.Version)
+
+	// the history is persisted for its environment and collection
+	data, err := history.Encode("dev", "c1")
+	assert.Nil(t, err)
+	restored := NewHistory(1)
+	assert.Nil(t, restored.Decode(data, "dev", "c1"))
+	assert.Equal(t, 1, len(restored.Versions()))
+	assert.Equal(t, versions[1].Checksum, restored.Versions()[0].Checksum)
+	assert.Equal(t, string(versions[1].Snapshot), string(restored.Versions()[0].Snapshot))
+	v4, _, _ := restored.Record(configuration("d"), "dev", "c1")
+	assert.Equal(t, 4, v4.Version)
+	assert.Nil(t, restored.Decode(data, "prod", "c1"))
+	assert.Equal(t, 0, len(restored.Versions()))
+	assert.NotNil(t, restored.Decode([]byte("{"), "dev", "c1"))
+}
+
+func TestDecodeBootstrap(t *testing.T) {
+	yamlConfig := `
+environments:
+  - name: Dev
+    environment_id: dev
+    features:
+      - name: F1
+        feature_id: f1
+        type: NUMERIC
+        enabled_value: 10
+        disabled_value: 0.5
+        enabled: true
+        segment_rules:
+          - rules:
+              - segments: [s1]
+            value: 20
+            order: 1
+            rollout_percentage: $default
+        schedule:
+          active_from: 2026-03-02T09:00:00Z
+    properties: []
+collections:
+  - name: C1
+    collection_id: c1
+segments:
+  - name: S1
+    segment_id: s1
+    rules:
+      - attribute_name: email
+        operator: endsWith
+        values: [ibm.com]
+`
+	jsonConfig := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"NUMERIC","enabled_value":10,"disabled_value":0.5,"enabled":true,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":20,"order":1,"rollout_percentage":"$default"}],"schedule":{"active_from":"2026-03-02T09:00:00Z"}}],"properties":[]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"attribute_name":"email","operator":"endsWith","values":["ibm.com"]}]}]}`
+
+	format, ok := BootstrapFormat("config/flags.YML", "")
+	assert.True(t, ok)
+	assert.Equal(t, "yaml", format)
+	decoded, err := DecodeBootstrap([]byte(yamlConfig), format)
+	assert.Nil(t, err)
+	fromYAML, err := ExtractConfigurations(decoded, "dev", "c1")
+	assert.Nil(t, err)
+	fromJSON, _ := ExtractConfigurations([]byte(jsonConfig), "dev", "c1")
+	assert.JSONEq(t, string(fromJSON), string(fromYAML))
+
+	// the errors have the line of the YAML document
+	_, err = DecodeBootstrap([]byte("environments:\n  - name: Dev\n    environment_id: dev: x\n"), "yaml")
+	assert.EqualError(t, err, "yaml: line 3: mapping values are not allowed in this context")
+	_, err = DecodeBootstrap([]byte("environments:\n  - name: Dev\n    name: Prod\n"), "yaml")
+	assert.EqualError(t, err, `yaml: line 3: mapping key "name" already defined at line 2`)
+	_, err = DecodeBootstrap([]byte(strings.Replace(yamlConfig, "order: 1", "order: first", 1)), "yaml")
+	assert.EqualError(t, err, "yaml: line 16: environments.0.features.0.segment_rules.0.order: cannot use string \"first\" as int")
+	_, err = DecodeBootstrap([]byte("? [a, b]\n: c\n"), "yaml")
+	assert.EqualError(t, err, "yaml: line 1: mapping keys must be scalars")
+	decoded, err = DecodeBootstrap([]byte(""), "yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "{}", string(decoded))
+
+	// the format is explicit, or that of the extension
+	format, ok = BootstrapFormat("flags.conf", "YAML")
+	assert.True(t, ok)
+	assert.Equal(t, "yaml", format)
+	_, ok = BootstrapFormat("flags.toml", "")
+	assert.False(t, ok)
+	_, err = DecodeBootstrap([]byte(""), "toml")
+	assert.EqualError(t, err, "unsupported bootstrap file format toml")
+
+	// more formats can be registered
+	RegisterBootstrapDecoder("fixed", func(data []byte) ([]byte, error) { return []byte(jsonConfig), nil }, ".fixed")
+	format, ok = BootstrapFormat("flags.fixed", "")
+	assert.True(t, ok)
+	decoded, err = DecodeBootstrap(nil, format)
+	assert.Nil(t, err)
+	assert.Equal(t, jsonConfig, string(decoded))
+}
+
+func TestMergeBootstrap(t *testing.T) {
+	base := `{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true},{"name":"F2","feature_id":"f2","type":"NUMERIC","enabled_value":1,"disabled_value":0,"segment_rules":[],"enabled":true}],"properties":[{"name":"P1","property_id":"p1","type":"STRING","value":"base","segment_rules":[]}]}],"collections":[{"name":"C1","collection_id":"c1"}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	region := `{"environments":[{"environment_id":"dev","features":[{"feature_id":"f1","enabled":false},{"feature_id":"f2","enabled":true},{"name":"F3","feature_id":"f3","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"property_id":"p1","value":"region"}]}],"segments":[{"segment_id":"s1","rules":[{"values":["example.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+
+	data, report, err := MergeBootstrap([]MergeLayer{{Data: []byte(base)}, {Data: []byte(region)}})
+	assert.Nil(t, err)
+	var config Config
+	assert.Nil(t, json.Unmarshal(data, &config))
+	assert.Equal(t, 1, len(config.Environments))
+	features := config.Environments[0].Features
+	assert.Equal(t, 3, len(features))
+	assert.Equal(t, "F1", features[0].Name)
+	assert.False(t, features[0].Enabled)
+	assert.Equal(t, "f3", features[2].FeatureID)
+	assert.Equal(t, "region", config.Environments[0].Properties[0].Value)
+	assert.Equal(t, "example.com", config.Segments[0].Rules[0].Values[0])
+	assert.Equal(t, "C1", config.Collections[0].Name)
+	assert.Equal(t, 2, report.Layers)
+	assert.Equal(t, "layer 1: overridden feature f1 (environment dev): enabled\n"+
+		"layer 1: added feature f3 (environment dev)\n"+
+		"layer 1: overridden property p1 (environment dev): value\n"+
+		"layer 1: overridden segment s1: rules", report.String())
+
+	// whole entries are replaced
+	data, report, err = MergeBootstrap([]MergeLayer{{Data: []byte(base)}, {Data: []byte(region), ReplaceEntries: true}})
+	assert.Nil(t, err)
+	config = Config{}
+	assert.Nil(t, json.Unmarshal(data, &config))
+	assert.Equal(t, "", config.Environments[0].Features[0].Name)
+	assert.Equal(t, "Dev", config.Environments[0].Name)
+	assert.Equal(t, MergeChange{Layer: 1, Kind: "feature", ID: "f2", EnvironmentID: "dev", Action: MergeActionReplaced}, report.Changes[1])
+
+	_, _, err = MergeBootstrap([]MergeLayer{{Data: []byte(base)}, {Data: []byte(`{"segments":[{"name":"S2"}]}`)}})
+	assert.EqualError(t, err, "layer 1: segments: entry without segment_id")
+	_, _, err = MergeBootstrap([]MergeLayer{{Data: []byte(base)}, {Data: []byte(`{"environments":{}}`)}})
+	assert.EqualError(t, err, "layer 1: environments is not an array")
+	_, _, err = MergeBootstrap([]MergeLayer{{Data: []byte(`[`)}})
+	assert.EqualError(t, err, "layer 0: unexpected end of JSON input")
+}
+
+func TestValidateFor(t *testing.T) {
+	config := []byte(`{"environments":[
+		{"name":"Dev","environment_id":"dev","features":[
+			{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":false,"order":1}],"enabled":true,"collections":[{"collection_id":"c1"}]},
+			{"name":"F2","feature_id":"f2","type":"BOOLEAN","enabled_value":"yes","disabled_value":false,"segment_rules":[{"rules":[{"segments":["s2"]}],"value":false,"order":1}],"enabled":true,"collections":[{"collection_id":"c2"}]}
+		],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":1,"segment_rules":[]}]},
+		{"name":"Prod","environment_id":"prod","features":[
+			{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":"yes","disabled_value":false,"segment_rules":[],"enabled":true}
+		],"properties":[]}
+	],"collections":[{"collection_id":"c1"},{"collection_id":"c2"}],"segments":[
+		{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]},
+		{"name":"S2","segment_id":"s2","rules":[{"values":["x"],"operator":"matches","attribute_name":"email"}]}
+	]}`)
+	assert.Equal(t, 3, len(Validate(config).Errors))
+
+	// the errors of the other environment, of the other collection and of its segment are not reported
+	report := ValidateFor(config, "dev", "c1")
+	assert.True(t, report.IsValid())
+	assert.Empty(t, report.Warnings)
+
+	report = ValidateFor(config, "dev", "c2")
+	assert.Equal(t, 2, len(report.Errors))
+	assert.Contains(t, report.String(), "error: feature f2 (environment dev): enabled_value yes is not a boolean")
+	assert.Contains(t, report.String(), `error: segment s2: rules[0] has unknown operator "matches"`)
+
+	report = ValidateFor(config, "prod", "c1")
+	assert.Equal(t, "error: feature f1 (environment prod): enabled_value yes is not a boolean", report.String())
+
+	// the referenced segments that do not exist are reported
+	report = ValidateFor([]byte(`{"environments":[{"name":"Dev","environment_id":"dev","features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["ghost"]}],"value":false,"order":1}],"enabled":true}],"properties":[]}],"collections":[],"segments":[]}`), "dev", "c1")
+	assert.Equal(t, `error: feature f1 (environment dev): segment_rules[0] references unknown segment "ghost"`, report.String())
+}
@@ -0,0 +1,241 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// KeyProvider : the source of the AES keys encrypting the persistent cache.
+// Keys are 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// CurrentKey returns the key encrypting the configurations, and the ID under which it is stored along with them.
+	CurrentKey() (keyID string, key []byte, err error)
+	// Key returns the key of the ID, decrypting the configurations encrypted with it.
+	Key(keyID string) ([]byte, error)
+}
+
+// KeyRing : a KeyProvider holding the current key and the previous ones. Configurations encrypted with a previous
+// key are still decrypted, and encrypted with the current key on the next save, which rotates the key.
+type KeyRing struct {
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+// NewKeyRing : Create a key ring encrypting with the key of currentKeyID, which keys must hold
+func NewKeyRing(currentKeyID string, keys map[string][]byte) (*KeyRing, error) {
+	ring := &KeyRing{currentKeyID: currentKeyID, keys: make(map[string][]byte, len(keys))}
+	for keyID, key := range keys {
+		if err := ValidateEncryptionKey(key); err != nil {
+			return nil, fmt.Errorf("key %q: %w", keyID, err)
+		}
+		ring.keys[keyID] = append([]byte{}, key...)
+	}
+	if _, ok := ring.keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("the current key %q is not in the key ring", currentKeyID)
+	}
+	return ring, nil
+}
+
+// NewStaticKeyProvider : Create a key provider with a single key, stored under the ID "default"
+func NewStaticKeyProvider(key []byte) (*KeyRing, error) {
+	return NewKeyRing("default", map[string][]byte{"default": key})
+}
+
+// CurrentKey : Get the current key and its ID
+func (r *KeyRing) CurrentKey() (string, []byte, error) {
+	return r.currentKeyID, r.keys[r.currentKeyID], nil
+}
+
+// Key : Get the key of the ID
+func (r *KeyRing) Key(keyID string) ([]byte, error) {
+	key, ok := r.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return key, nil
+}
+
+// ValidateEncryptionKey : Check that the key is an AES-128, AES-192 or AES-256 key
+func ValidateEncryptionKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	}
+	return fmt.Errorf("invalid key length %d, expected 16, 24 or 32 bytes", len(key))
+}
+
+// EncryptedCacheStore : a CacheStore encrypting the configurations of another store with AES-GCM.
+type EncryptedCacheStore struct {
+	store       CacheStore
+	keyProvider KeyProvider
+}
+
+// ErrCacheDecryption : the error of EncryptedCacheStore.Load when the stored configurations cannot be decrypted,
+// e.g. the key changed without keeping the previous one in a KeyRing, or the configurations are not encrypted.
+var ErrCacheDecryption = errors.New("the persistent cache cannot be decrypted")
+
+// encryptedCache is the format of the encrypted configurations. The key ID is authenticated along with them.
+type encryptedCache struct {
+	Algorithm  string `json:"algorithm"`
+	KeyID      string `json:"key_id"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+const encryptionAlgorithm = "AES-GCM"
+
+// NewEncryptedCacheStore : Encrypt the configurations of the store with the keys of the key provider
+func NewEncryptedCacheStore(store CacheStore, keyProvider KeyProvider) *EncryptedCacheStore {
+	return &EncryptedCacheStore{store: store, keyProvider: keyProvider}
+}
+
+// Store : Get the store holding the encrypted configurations
+func (s *EncryptedCacheStore) Store() CacheStore {
+	return s.store
+}
+
+// Load : Read and decrypt the configurations
+func (s *EncryptedCacheStore) Load() ([]byte, error) {
+	data, err := s.store.Load()
+	if err != nil || data == nil {
+		return data, err
+	}
+	return s.decrypt(data)
+}
+
+// LoadBackup : Read and decrypt the backup of the store, if it keeps one
+func (s *EncryptedCacheStore) LoadBackup() ([]byte, error) {
+	backupStore, ok := s.store.(BackupCacheStore)
+	if !ok {
+		return nil, nil
+	}
+	data, err := backupStore.LoadBackup()
+	if err != nil || data == nil {
+		return data, err
+	}
+	return s.decrypt(data)
+}
+
+// Save : Encrypt the configurations with the current key, and write them. A backup of the store that is not
+// encrypted, i.e. the configurations saved before the encryption was enabled, is discarded.
+func (s *EncryptedCacheStore) Save(data []byte) error {
+	file, err := s.encrypt(data)
+	if err != nil {
+		return err
+	}
+	if err = s.store.Save(file); err != nil {
+		return err
+	}
+	if backupStore, ok := s.store.(BackupCacheStore); ok {
+		if backup, err := backupStore.LoadBackup(); err == nil && backup != nil && !isEncrypted(backup) {
+			return backupStore.DiscardBackup()
+		}
+	}
+	return nil
+}
+
+// SaveWithBackup : Encrypt the configurations with the current key, and write them, keeping the replaced ones as
+// the backup if the store keeps one
+func (s *EncryptedCacheStore) SaveWithBackup(data []byte) error {
+	backupStore, ok := s.store.(BackupCacheStore)
+	if !ok {
+		return s.Save(data)
+	}
+	file, err := s.encrypt(data)
+	if err != nil {
+		return err
+	}
+	return backupStore.SaveWithBackup(file)
+}
+
+// DiscardBackup : Remove the backup of the store, if it keeps one
+func (s *EncryptedCacheStore) DiscardBackup() error {
+	if backupStore, ok := s.store.(BackupCacheStore); ok {
+		return backupStore.DiscardBackup()
+	}
+	return nil
+}
+
+// Lock : Lock the underlying store
+func (s *EncryptedCacheStore) Lock() (func(), error) {
+	return s.store.Lock()
+}
+
+// encrypt encrypts the configurations with the current key, in the format of encryptedCache.
+func (s *EncryptedCacheStore) encrypt(data []byte) ([]byte, error) {
+	keyID, key, err := s.keyProvider.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := encryptedCache{Algorithm: encryptionAlgorithm, KeyID: keyID, Nonce: make([]byte, gcm.NonceSize())}
+	if _, err = rand.Read(encrypted.Nonce); err != nil {
+		return nil, err
+	}
+	encrypted.Ciphertext = gcm.Seal(nil, encrypted.Nonce, data, []byte(keyID))
+	return json.Marshal(encrypted)
+}
+
+// isEncrypted reports whether the stored data is in the format of the encrypted configurations.
+func isEncrypted(data []byte) bool {
+	var encrypted encryptedCache
+	return json.Unmarshal(data, &encrypted) == nil && encrypted.Algorithm == encryptionAlgorithm
+}
+
+func (s *EncryptedCacheStore) decrypt(data []byte) ([]byte, error) {
+	if !isEncrypted(data) {
+		return nil, fmt.Errorf("%w: the stored configurations are not encrypted", ErrCacheDecryption)
+	}
+	var encrypted encryptedCache
+	_ = json.Unmarshal(data, &encrypted)
+	key, err := s.keyProvider.Key(encrypted.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCacheDecryption, err.Error())
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCacheDecryption, err.Error())
+	}
+	if len(encrypted.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrCacheDecryption)
+	}
+	plaintext, err := gcm.Open(nil, encrypted.Nonce, encrypted.Ciphertext, []byte(encrypted.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w with the key %q, it was encrypted with another key or tampered with", ErrCacheDecryption, encrypted.KeyID)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if err := ValidateEncryptionKey(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,227 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	bolt "go.etcd.io/bbolt"
+)
+
+// CacheStore : the backend of the persistent cache, which keeps the last known good configurations across restarts.
+type CacheStore interface {
+	// Load returns the stored configurations, or nil if none are stored yet.
+	Load() ([]byte, error)
+	// Save replaces the stored configurations.
+	Save(data []byte) error
+	// Lock acquires exclusive access to the store, and returns the function releasing it.
+	// Stores shared between processes lock across processes.
+	Lock() (unlock func(), err error)
+}
+
+// BackupCacheStore : a CacheStore keeping the previously saved configurations, which LoadConfigurations falls back
+// to when the stored configurations are corrupted.
+type BackupCacheStore interface {
+	CacheStore
+	// LoadBackup returns the configurations saved before the stored ones, or nil if there are none.
+	LoadBackup() ([]byte, error)
+	// SaveWithBackup replaces the stored configurations like Save, and keeps the replaced ones as the backup.
+	SaveWithBackup(data []byte) error
+	// DiscardBackup removes the backup, if there is one.
+	DiscardBackup() error
+}
+
+// FileCacheStore : a CacheStore keeping the configurations in the appconfiguration.json file of a directory.
+// It is the store of ContextOptions.PersistentCacheDirectory. Lock uses an advisory file lock where the platform supports it.
+type FileCacheStore struct {
+	directory string
+}
+
+// NewFileCacheStore : Create a store keeping the configurations in the appconfiguration.json file of the directory
+func NewFileCacheStore(directory string) *FileCacheStore {
+	return &FileCacheStore{directory: SanitizePath(directory)}
+}
+
+// Path : Get the path of the cache file
+func (s *FileCacheStore) Path() string {
+	return filepath.Join(s.directory, constants.ConfigurationFile)
+}
+
+// Load : Read the cache file. A missing file is not an error.
+func (s *FileCacheStore) Load() ([]byte, error) {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	data, err := os.ReadFile(s.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// BackupPath : Get the path of the previous cache file, kept by SaveWithBackup
+func (s *FileCacheStore) BackupPath() string {
+	return s.Path() + ".bak"
+}
+
+// Save : Write the cache file atomically. The backup is left unchanged.
+func (s *FileCacheStore) Save(data []byte) error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	return WriteFileAtomic(s.Path(), data, 0644)
+}
+
+// SaveWithBackup : Write the cache file atomically, and keep the replaced file as the backup, see LoadBackup.
+// The replaced file is moved once the new one is synced to the disk.
+func (s *FileCacheStore) SaveWithBackup(data []byte) error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	tmp, err := writeTempFile(s.Path(), data, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err = os.Rename(s.Path(), s.BackupPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err = os.Rename(tmp, s.Path()); err != nil {
+		return err
+	}
+	syncDir(s.directory)
+	return nil
+}
+
+// LoadBackup : Read the previous cache file. A missing file is not an error.
+func (s *FileCacheStore) LoadBackup() ([]byte, error) {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	data, err := os.ReadFile(s.BackupPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DiscardBackup : Remove the previous cache file. A missing file is not an error.
+func (s *FileCacheStore) DiscardBackup() error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	if err := os.Remove(s.BackupPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Lock : Lock the cache file against the other processes sharing the directory
+func (s *FileCacheStore) Lock() (func(), error) {
+	return lockFile(s.Path() + ".lock")
+}
+
+// BoltCacheStore : a CacheStore keeping the configurations in a BoltDB file, an embedded key-value store.
+// The file is locked by the store until Close, so it cannot be shared by several processes at once.
+type BoltCacheStore struct {
+	db *bolt.DB
+	mu sync.Mutex
+}
+
+var boltBucket = []byte("appconfiguration")
+var boltKey = []byte("configurations")
+
+// NewBoltCacheStore : Open, or create, the BoltDB file at path. It fails after a second if another process holds the file.
+func NewBoltCacheStore(path string) (*BoltCacheStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltCacheStore{db: db}, nil
+}
+
+// Load : Read the configurations from the BoltDB file
+func (s *BoltCacheStore) Load() ([]byte, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(boltBucket); bucket != nil {
+			if value := bucket.Get(boltKey); value != nil {
+				data = append([]byte{}, value...)
+			}
+		}
+		return nil
+	})
+	return data, err
+}
+
+// Save : Write the configurations to the BoltDB file, in a single transaction
+func (s *BoltCacheStore) Save(data []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(boltBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(boltKey, data)
+	})
+}
+
+// Lock : Lock the store. BoltDB already holds the file for this process, so the lock is in-process.
+func (s *BoltCacheStore) Lock() (func(), error) {
+	s.mu.Lock()
+	return s.mu.Unlock, nil
+}
+
+// Close : Close the BoltDB file
+func (s *BoltCacheStore) Close() error {
+	return s.db.Close()
+}
+
+// MemoryCacheStore : a CacheStore keeping the configurations in memory, e.g. for tests.
+type MemoryCacheStore struct {
+	data []byte
+	mu   sync.Mutex
+	lock sync.Mutex
+}
+
+// NewMemoryCacheStore : Create an empty in-memory store
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{}
+}
+
+// Load : Get the stored configurations
+func (s *MemoryCacheStore) Load() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, nil
+	}
+	return append([]byte{}, s.data...), nil
+}
+
+// Save : Store the configurations
+func (s *MemoryCacheStore) Save(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = append([]byte{}, data...)
+	return nil
+}
+
+// Lock : Lock the store
+func (s *MemoryCacheStore) Lock() (func(), error) {
+	s.lock.Lock()
+	return s.lock.Unlock, nil
+}
@@ -17,11 +17,7 @@
 package utils
 
 import (
-	"encoding/json"
-	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
-	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
-	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
-	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -38,36 +34,48 @@ func SanitizePath(_path string) string {
 	return filepath.FromSlash(path.Clean("/" + strings.Trim(_path, "/")))
 }
 
-// StoreFiles : Store Files
-func StoreFiles(content, basePath string) {
-	fileMutex.Lock()
-	defer fileMutex.Unlock()
-
-	log.Debug(messages.StoreFile)
+// WriteFileAtomic : Write the file through a temporary file of the same directory, synced to the disk and renamed
+// over the file, so that a crash never leaves the file truncated.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	tmp, err := writeTempFile(filePath, data, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err = os.Rename(tmp, filePath); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(filePath))
+	return nil
+}
 
-	file, err := json.MarshalIndent(json.RawMessage(content), "", "\t")
+// writeTempFile writes the data to a temporary file of the directory of filePath, synced to the disk, and returns
+// its path. The temporary file is removed on error.
+func writeTempFile(filePath string, data []byte, perm os.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp*")
 	if err != nil {
-		log.Error(messages.EncodeJSONErr, err)
-		return
+		return "", err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
 	}
-	sanitizedFilePath := filepath.Join(SanitizePath(basePath), constants.ConfigurationFile)
-	err = ioutil.WriteFile(sanitizedFilePath, file, 0644)
 	if err != nil {
-		log.Error(messages.WriteFileErr, err)
-		return
+		os.Remove(tmp.Name())
+		return "", err
 	}
+	return tmp.Name(), nil
 }
 
-// ReadFiles reads file from the file path
-func ReadFiles(filePath string) []byte {
-	fileMutex.Lock()
-	defer fileMutex.Unlock()
-
-	log.Debug(messages.ReadFile)
-	file, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		log.Error(messages.ReadFileErr, err)
-		return []byte(`{}`)
+// syncDir flushes the rename of a file of the directory to the disk. Platforms that cannot sync a directory skip it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
 	}
-	return file
 }
@@ -0,0 +1,113 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"crypto/sha256"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// FileWatcher : polls files, and calls a function when the content of a file changes. The files are checked through
+// their symbolic links, so that the symbolic link swaps of the Kubernetes ConfigMap volumes are seen as changes.
+// A file that is missing, e.g. during a swap, keeps its previous content until it is back.
+type FileWatcher struct {
+	paths    []string
+	interval time.Duration
+	onChange func()
+	states   []fileState
+	stop     chan struct{}
+	once     sync.Once
+	running  sync.WaitGroup
+}
+
+type fileState struct {
+	info     os.FileInfo
+	checksum [sha256.Size]byte
+}
+
+// NewFileWatcher : Create a watcher of the files, polled every interval, calling onChange after the files changed
+func NewFileWatcher(paths []string, interval time.Duration, onChange func()) *FileWatcher {
+	return &FileWatcher{
+		paths:    append([]string{}, paths...),
+		interval: interval,
+		onChange: onChange,
+		states:   make([]fileState, len(paths)),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start : Record the current content of the files, and poll them in the background until Stop
+func (w *FileWatcher) Start() {
+	w.poll()
+	w.running.Add(1)
+	go func() {
+		defer w.running.Done()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				if w.poll() {
+					w.onChange()
+				}
+			}
+		}
+	}()
+}
+
+// Stop : Stop polling the files, and wait for a running onChange to return. Stop must not be called from onChange.
+func (w *FileWatcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	w.running.Wait()
+}
+
+// poll reports whether the content of a file changed since the previous poll, or a file appeared.
+func (w *FileWatcher) poll() bool {
+	changed := false
+	for i, path := range w.paths {
+		// os.Stat follows the symbolic links
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Debug(messages.WatchFileErr, err)
+			continue
+		}
+		previous := w.states[i]
+		if previous.info != nil && os.SameFile(previous.info, info) && previous.info.ModTime().Equal(info.ModTime()) && previous.info.Size() == info.Size() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Debug(messages.WatchFileErr, err)
+			continue
+		}
+		checksum := sha256.Sum256(data)
+		if previous.info == nil || checksum != previous.checksum {
+			changed = true
+		}
+		w.states[i] = fileState{info: info, checksum: checksum}
+	}
+	return changed
+}
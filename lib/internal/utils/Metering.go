@@ -36,6 +36,7 @@ type Usages struct {
 	SegmentID      interface{} `json:"segment_id"`
 	EvaluationTime string      `json:"evaluation_time"`
 	Count          int64       `json:"count"`
+	VariantKey     string      `json:"variant_key,omitempty"`
 }
 
 // CollectionUsages : CollectionUsages struct
@@ -48,6 +49,22 @@ type CollectionUsages struct {
 type featureMetric struct {
 	count          int64
 	evaluationTime string
+	variants       map[string]featureMetric // the evaluations of a multivariate feature flag, by variant key
+}
+
+// add records an evaluation, of the variant if variantKey is not empty.
+func (fm *featureMetric) add(evaluationTime, variantKey string) {
+	if len(variantKey) == 0 {
+		fm.count++
+		fm.evaluationTime = evaluationTime
+		return
+	}
+	if fm.variants == nil {
+		fm.variants = make(map[string]featureMetric)
+	}
+	variant := fm.variants[variantKey]
+	variant.add(evaluationTime, "")
+	fm.variants[variantKey] = variant
 }
 
 // Metering : Metering struct
@@ -92,6 +109,10 @@ func (mt *Metering) Init(guid string, environmentID string, collectionID string)
 }
 
 func (mt *Metering) addMetering(guid string, environmentID string, collectionID string, entityID string, segmentID string, featureID string, propertyID string) {
+	mt.addVariantMetering(guid, environmentID, collectionID, entityID, segmentID, featureID, propertyID, "")
+}
+
+func (mt *Metering) addVariantMetering(guid string, environmentID string, collectionID string, entityID string, segmentID string, featureID string, propertyID string, variantKey string) {
 	log.Debug(messages.AddMetering)
 	defer GracefullyHandleError()
 	mt.mu.Lock()
@@ -100,8 +121,7 @@ func (mt *Metering) addMetering(guid string, environmentID string, collectionID
 		t.Year(), t.Month(), t.Day(),
 		t.Hour(), t.Minute(), t.Second())
 	var fm featureMetric
-	fm.evaluationTime = formattedTime
-	fm.count = 1
+	fm.add(formattedTime, variantKey)
 	meteringData := make(map[string]map[string]map[string]map[string]map[string]map[string]featureMetric)
 	var modifyKey string
 	if featureID != "" {
@@ -123,8 +143,7 @@ func (mt *Metering) addMetering(guid string, environmentID string, collectionID
 						entityIDVal := modifyKeyVal[entityID]
 						if _, ok := entityIDVal[segmentID]; ok {
 							segmentIDVal := entityIDVal[segmentID]
-							segmentIDVal.evaluationTime = formattedTime
-							segmentIDVal.count = segmentIDVal.count + 1
+							segmentIDVal.add(formattedTime, variantKey)
 							entityIDVal[segmentID] = segmentIDVal
 						} else {
 							entityIDVal[segmentID] = fm
@@ -182,6 +201,12 @@ func (mt *Metering) RecordEvaluation(featureID string, propertyID string, entity
 	log.Debug(messages.RecordEval)
 	mt.addMetering(mt.guid, mt.EnvironmentID, mt.CollectionID, entityID, segmentID, featureID, propertyID)
 }
+
+// RecordVariantEvaluation : Record the evaluation of a multivariate feature flag, along with the key of the served variant
+func (mt *Metering) RecordVariantEvaluation(featureID string, entityID string, segmentID string, variantKey string) {
+	log.Debug(messages.RecordEval)
+	mt.addVariantMetering(mt.guid, mt.EnvironmentID, mt.CollectionID, entityID, segmentID, featureID, "", variantKey)
+}
 func (mt *Metering) buildRequestBody(sendMeteringData map[string]map[string]map[string]map[string]map[string]map[string]featureMetric, guidMap map[string][]CollectionUsages, key string) {
 
 	for guid, environmentMap := range sendMeteringData {
@@ -211,9 +236,18 @@ func (mt *Metering) buildRequestBody(sendMeteringData map[string]map[string]map[
 							} else {
 								usages.SegmentID = segmentID
 							}
-							usages.EvaluationTime = val.evaluationTime
-							usages.Count = val.count
-							usagesArray = append(usagesArray, usages)
+							if val.count > 0 {
+								usages.EvaluationTime = val.evaluationTime
+								usages.Count = val.count
+								usagesArray = append(usagesArray, usages)
+							}
+							// one usage per variant
+							for variantKey, variant := range val.variants {
+								usages.EvaluationTime = variant.evaluationTime
+								usages.Count = variant.count
+								usages.VariantKey = variantKey
+								usagesArray = append(usagesArray, usages)
+							}
 						}
 					}
 				}
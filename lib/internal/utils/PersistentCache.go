@@ -0,0 +1,190 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// CacheMetadata : the metadata stored along with the configurations in the persistent cache.
+type CacheMetadata struct {
+	FetchedAt     time.Time `json:"fetched_at"`
+	SDKVersion    string    `json:"sdk_version"`
+	EnvironmentID string    `json:"environment_id"`
+	CollectionID  string    `json:"collection_id"`
+	// Checksum is the hex encoded SHA-256 of the compacted configurations.
+	Checksum string `json:"checksum"`
+}
+
+// cacheEnvelope is the format of the persistent cache. Caches written by older versions of the SDK hold the bare
+// configurations, and are read as such.
+type cacheEnvelope struct {
+	Metadata       *CacheMetadata  `json:"metadata"`
+	Configurations json.RawMessage `json:"configurations"`
+}
+
+// errContextMismatch is returned by openEnvelope for a cache written for another environment or collection.
+var errContextMismatch = errors.New("context mismatch")
+
+// StoreConfigurations : Store the configurations, fetched now, in the persistent cache store, holding the lock of the store.
+// The configurations are wrapped with their metadata and checksum, which LoadConfigurations verifies.
+func StoreConfigurations(store CacheStore, content, environmentID, collectionID string) {
+	StoreConfigurationsWithMetadata(store, content, CacheMetadata{
+		FetchedAt:     time.Now(),
+		EnvironmentID: environmentID,
+		CollectionID:  collectionID,
+	})
+}
+
+// StoreConfigurationsWithMetadata : Store the configurations like StoreConfigurations, with the fetch time, the
+// environment and the collection of the metadata. The SDK version and the checksum are set by the function.
+func StoreConfigurationsWithMetadata(store CacheStore, content string, metadata CacheMetadata) {
+	log.Debug(messages.StoreFile)
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(content)); err != nil {
+		log.Error(messages.EncodeJSONErr, err)
+		return
+	}
+	metadata.FetchedAt = metadata.FetchedAt.UTC()
+	metadata.SDKVersion = constants.SDKVersion
+	metadata.Checksum = checksum(compacted.Bytes())
+	envelope := cacheEnvelope{Metadata: &metadata, Configurations: compacted.Bytes()}
+	file, err := json.MarshalIndent(envelope, "", "\t")
+	if err != nil {
+		log.Error(messages.EncodeJSONErr, err)
+		return
+	}
+	unlock, err := store.Lock()
+	if err != nil {
+		log.Error(messages.WriteFileErr, err)
+		return
+	}
+	defer unlock()
+	if backupStore, ok := store.(BackupCacheStore); ok {
+		err = saveWithBackup(backupStore, file, metadata.EnvironmentID, metadata.CollectionID)
+	} else {
+		err = store.Save(file)
+	}
+	if err != nil {
+		log.Error(messages.WriteFileErr, err)
+		return
+	}
+}
+
+// saveWithBackup saves the persistent cache, and keeps the replaced one as the backup if it verifies. A replaced
+// cache that is corrupted leaves the backup unchanged, so that the last good configurations can still be fallen
+// back to.
+func saveWithBackup(store BackupCacheStore, data []byte, environmentID, collectionID string) error {
+	if current, err := store.Load(); err == nil && current != nil {
+		if _, _, err = openEnvelope(current, environmentID, collectionID); err == nil {
+			return store.SaveWithBackup(data)
+		}
+	}
+	return store.Save(data)
+}
+
+// LoadConfigurations : Load the configurations from the persistent cache store, holding the lock of the store.
+// Configurations failing the checksum fall back to the backup of the store, if it keeps one. Configurations
+// written for another environment or collection are ignored. It returns `{}` when no valid configurations are found.
+func LoadConfigurations(store CacheStore, environmentID, collectionID string) []byte {
+	configurations, _ := LoadConfigurationsWithMetadata(store, environmentID, collectionID)
+	return configurations
+}
+
+// LoadConfigurationsWithMetadata : Load the configurations like LoadConfigurations, along with their metadata.
+// The metadata is zero for a persistent cache written by an older version of the SDK.
+func LoadConfigurationsWithMetadata(store CacheStore, environmentID, collectionID string) ([]byte, CacheMetadata) {
+	log.Debug(messages.ReadFile)
+	unlock, err := store.Lock()
+	if err != nil {
+		log.Error(messages.ReadFileErr, err)
+		return []byte(`{}`), CacheMetadata{}
+	}
+	defer unlock()
+	data, err := store.Load()
+	if err != nil {
+		log.Error(messages.ReadFileErr, err)
+	} else if data != nil {
+		configurations, metadata, err := openEnvelope(data, environmentID, collectionID)
+		if err == nil {
+			return configurations, metadata
+		}
+		if errors.Is(err, errContextMismatch) {
+			log.Warn(messages.PersistentCacheContextMismatch, err)
+			return []byte(`{}`), CacheMetadata{}
+		}
+		log.Error(messages.PersistentCacheCorrupted, err)
+	}
+	backupStore, ok := store.(BackupCacheStore)
+	if !ok {
+		return []byte(`{}`), CacheMetadata{}
+	}
+	backup, err := backupStore.LoadBackup()
+	if err != nil {
+		log.Error(messages.ReadFileErr, err)
+		return []byte(`{}`), CacheMetadata{}
+	}
+	if backup == nil {
+		return []byte(`{}`), CacheMetadata{}
+	}
+	configurations, metadata, err := openEnvelope(backup, environmentID, collectionID)
+	if err != nil {
+		log.Error(messages.PersistentCacheCorrupted, err)
+		return []byte(`{}`), CacheMetadata{}
+	}
+	log.Warn(messages.PersistentCacheBackupUsed)
+	return configurations, metadata
+}
+
+// openEnvelope verifies the persistent cache and returns its configurations and metadata.
+func openEnvelope(data []byte, environmentID, collectionID string) ([]byte, CacheMetadata, error) {
+	var envelope cacheEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, CacheMetadata{}, err
+	}
+	if envelope.Metadata == nil {
+		return data, CacheMetadata{}, nil
+	}
+	metadata := *envelope.Metadata
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, envelope.Configurations); err != nil {
+		return nil, metadata, err
+	}
+	if sum := checksum(compacted.Bytes()); sum != metadata.Checksum {
+		return nil, metadata, fmt.Errorf("checksum %s does not match the expected %s", sum, metadata.Checksum)
+	}
+	if metadata.EnvironmentID != environmentID || metadata.CollectionID != collectionID {
+		return nil, metadata, fmt.Errorf("%w: written for environment %q and collection %q", errContextMismatch, metadata.EnvironmentID, metadata.CollectionID)
+	}
+	return compacted.Bytes(), metadata, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedCacheStore(t *testing.T) {
+	mockLogger()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+
+	_, err := NewStaticKeyProvider([]byte("short"))
+	assert.EqualError(t, err, `key "default": invalid key length 5, expected 16, 24 or 32 bytes`)
+	_, err = NewKeyRing("k2", map[string][]byte{"k1": oldKey})
+	assert.EqualError(t, err, `the current key "k2" is not in the key ring`)
+
+	fileStore := NewFileCacheStore(t.TempDir())
+	provider, err := NewStaticKeyProvider(oldKey)
+	assert.Nil(t, err)
+	store := NewEncryptedCacheStore(fileStore, provider)
+	StoreConfigurations(store, `{"segments":[{"rules":[{"values":["ibm.com"]}]}]}`, "dev", "c1")
+	assert.Equal(t, `{"segments":[{"rules":[{"values":["ibm.com"]}]}]}`, string(LoadConfigurations(store, "dev", "c1")))
+
+	// the file holds no plain text
+	data, err := fileStore.Load()
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(data), "ibm.com"))
+	assert.Contains(t, string(data), `"key_id":"default"`)
+
+	// a key ring keeping the previous key decrypts the cache, and encrypts the next save with the new key
+	ring, err := NewKeyRing("k2", map[string][]byte{"default": oldKey, "k2": newKey})
+	assert.Nil(t, err)
+	rotated := NewEncryptedCacheStore(fileStore, ring)
+	assert.Equal(t, `{"segments":[{"rules":[{"values":["ibm.com"]}]}]}`, string(LoadConfigurations(rotated, "dev", "c1")))
+	StoreConfigurations(rotated, `{"features":[]}`, "dev", "c1")
+	data, _ = fileStore.Load()
+	assert.Contains(t, string(data), `"key_id":"k2"`)
+
+	// the old key alone cannot decrypt it
+	_, err = store.Load()
+	assert.True(t, errors.Is(err, ErrCacheDecryption))
+	assert.EqualError(t, err, `the persistent cache cannot be decrypted: unknown key "k2"`)
+
+	// neither can another key with the same ID
+	other, _ := NewKeyRing("k2", map[string][]byte{"k2": bytes.Repeat([]byte{3}, 16)})
+	_, err = NewEncryptedCacheStore(fileStore, other).Load()
+	assert.EqualError(t, err, `the persistent cache cannot be decrypted with the key "k2", it was encrypted with another key or tampered with`)
+	assert.Equal(t, `{}`, string(LoadConfigurations(NewEncryptedCacheStore(fileStore, other), "dev", "c1")))
+
+	// plain text caches are not read
+	plainStore := NewMemoryCacheStore()
+	StoreConfigurations(plainStore, `{"features":[]}`, "dev", "c1")
+	_, err = NewEncryptedCacheStore(plainStore, ring).Load()
+	assert.EqualError(t, err, "the persistent cache cannot be decrypted: the stored configurations are not encrypted")
+
+	// enabling the encryption leaves no plain text backup
+	plainFileStore := NewFileCacheStore(t.TempDir())
+	StoreConfigurations(plainFileStore, `{"segments":[{"rules":[{"values":["ibm.com"]}]}]}`, "dev", "c1")
+	StoreConfigurations(NewEncryptedCacheStore(plainFileStore, provider), `{"features":[]}`, "dev", "c1")
+	_, err = os.Stat(plainFileStore.BackupPath())
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// the next backup is encrypted
+	StoreConfigurations(NewEncryptedCacheStore(plainFileStore, provider), `{"properties":[]}`, "dev", "c1")
+	backup, err := os.ReadFile(plainFileStore.BackupPath())
+	assert.Nil(t, err)
+	assert.True(t, isEncrypted(backup))
+}
@@ -0,0 +1,77 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStores(t *testing.T) {
+	mockLogger()
+	dir := t.TempDir()
+	boltStore, err := NewBoltCacheStore(filepath.Join(dir, "cache.db"))
+	assert.Nil(t, err)
+	defer boltStore.Close()
+	stores := map[string]CacheStore{
+		"file":   NewFileCacheStore(dir),
+		"bolt":   boltStore,
+		"memory": NewMemoryCacheStore(),
+	}
+	for name, store := range stores {
+		data, err := store.Load()
+		assert.Nil(t, err, name)
+		assert.Nil(t, data, name)
+		assert.Equal(t, []byte(`{}`), LoadConfigurations(store, "dev", "c1"), name)
+
+		StoreConfigurations(store, `{"key":"value"}`, "dev", "c1")
+		assert.Equal(t, `{"key":"value"}`, string(LoadConfigurations(store, "dev", "c1")), name)
+
+		// invalid JSON is not stored
+		StoreConfigurations(store, "", "dev", "c1")
+		assert.Equal(t, `{"key":"value"}`, string(LoadConfigurations(store, "dev", "c1")), name)
+
+		unlock, err := store.Lock()
+		assert.Nil(t, err, name)
+		released := make(chan bool)
+		go func() {
+			unlockAgain, _ := store.Lock()
+			released <- true
+			unlockAgain()
+		}()
+		select {
+		case <-released:
+			t.Errorf("%s: the store was locked twice", name)
+		case <-time.After(50 * time.Millisecond):
+		}
+		unlock()
+		<-released
+	}
+
+	// the file store is the store of the persistent cache directory
+	data, err := os.ReadFile(filepath.Join(dir, constants.ConfigurationFile))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "\"configurations\": {\n\t\t\"key\": \"value\"\n\t}")
+
+	_, err = NewBoltCacheStore(filepath.Join(dir, "missing", "cache.db"))
+	assert.NotNil(t, err)
+}
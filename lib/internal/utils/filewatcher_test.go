@@ -0,0 +1,113 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWatcher(t *testing.T) {
+	mockLogger()
+	dir := t.TempDir()
+	// the layout of a Kubernetes ConfigMap volume: the file links to the ..data link of the current version
+	writeVersion := func(version, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, version), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, version, "flights.json"), []byte(content), 0644))
+		assert.Nil(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("v1", `{"version":1}`)
+	path := filepath.Join(dir, "flights.json")
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "flights.json"), path))
+
+	changes := make(chan struct{}, 10)
+	watcher := NewFileWatcher([]string{path}, 10*time.Millisecond, func() { changes <- struct{}{} })
+	watcher.Start()
+	defer watcher.Stop()
+
+	// the link swap is a change
+	writeVersion("v2", `{"version":2}`)
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("Test failed: the link swap is not seen")
+	}
+
+	// the same content is not
+	writeVersion("v3", `{"version":2}`)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 0, len(changes))
+
+	// a missing file keeps its content
+	assert.Nil(t, os.Remove(filepath.Join(dir, "..data")))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, len(changes))
+	writeVersion("v4", `{"version":4}`)
+	assert.Eventually(t, func() bool { return len(changes) == 1 }, time.Second, 10*time.Millisecond)
+
+	// no more changes after Stop
+	watcher.Stop()
+	time.Sleep(20 * time.Millisecond)
+	<-changes
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "v4", "flights.json"), []byte(`{"version":5}`), 0644))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestFileWatcherStopWaitsForOnChange(t *testing.T) {
+	mockLogger()
+	path := filepath.Join(t.TempDir(), "flights.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"version":1}`), 0644))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var finished atomic.Bool
+	watcher := NewFileWatcher([]string{path}, 10*time.Millisecond, func() {
+		close(started)
+		<-release
+		finished.Store(true)
+	})
+	watcher.Start()
+	assert.Nil(t, os.WriteFile(path, []byte(`{"version":2}`), 0644))
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Test failed: the change is not seen")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		watcher.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Test failed: Stop returned before onChange")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+	assert.True(t, finished.Load())
+
+	// a watcher that is not started stops at once
+	NewFileWatcher([]string{path}, time.Second, func() {}).Stop()
+}
@@ -206,6 +206,28 @@ func TestBuildRequestBody(t *testing.T) {
 
 }
 
+func TestRecordVariantEvaluation(t *testing.T) {
+	m := GetMeteringInstance()
+	m.Init("guid", "dev", "c1")
+	m.RecordVariantEvaluation("f1", "e1", "s1", "control")
+	m.RecordVariantEvaluation("f1", "e1", "s1", "treatment")
+	m.RecordVariantEvaluation("f1", "e1", "s1", "control")
+	segmentVal := m.meteringFeatureData["guid"]["dev"]["c1"]["f1"]["e1"]["s1"]
+	assert.Equal(t, int64(0), segmentVal.count)
+	assert.Equal(t, int64(2), segmentVal.variants["control"].count)
+	assert.Equal(t, int64(1), segmentVal.variants["treatment"].count)
+
+	// each variant is reported with its own count
+	guidMap := make(map[string][]CollectionUsages)
+	m.buildRequestBody(m.meteringFeatureData, guidMap, "feature_id")
+	counts := make(map[string]int64)
+	for _, usages := range guidMap["guid"][0].Usages {
+		counts[usages.VariantKey] = usages.Count
+	}
+	assert.Equal(t, map[string]int64{"control": 2, "treatment": 1}, counts)
+	resetMeteringInstance()
+}
+
 func TestSendToServer(t *testing.T) {
 
 	// test send to server with backend returning success
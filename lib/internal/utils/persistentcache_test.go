@@ -0,0 +1,98 @@
+/**
+ * (C) Copyright IBM Corp. 2026.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentCacheIntegrity(t *testing.T) {
+	mockLogger()
+	dir := t.TempDir()
+	store := NewFileCacheStore(dir)
+
+	StoreConfigurations(store, `{"version":1}`, "dev", "c1")
+	StoreConfigurations(store, `{"version":2}`, "dev", "c1")
+	assert.Equal(t, `{"version":2}`, string(LoadConfigurations(store, "dev", "c1")))
+
+	// the metadata is stored along with the configurations
+	data, err := store.Load()
+	assert.Nil(t, err)
+	var envelope cacheEnvelope
+	assert.Nil(t, json.Unmarshal(data, &envelope))
+	assert.Equal(t, constants.SDKVersion, envelope.Metadata.SDKVersion)
+	assert.Equal(t, "dev", envelope.Metadata.EnvironmentID)
+	assert.Equal(t, "c1", envelope.Metadata.CollectionID)
+	assert.False(t, envelope.Metadata.FetchedAt.IsZero())
+	_, metadata := LoadConfigurationsWithMetadata(store, "dev", "c1")
+	assert.Equal(t, *envelope.Metadata, metadata)
+
+	// the fetch time can be set, e.g. to the modification time of a bootstrap file
+	fetchedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	memoryStore := NewMemoryCacheStore()
+	StoreConfigurationsWithMetadata(memoryStore, `{"version":1}`, CacheMetadata{FetchedAt: fetchedAt, EnvironmentID: "dev", CollectionID: "c1"})
+	_, metadata = LoadConfigurationsWithMetadata(memoryStore, "dev", "c1")
+	assert.Equal(t, fetchedAt, metadata.FetchedAt)
+
+	// the previous file is kept, and no temporary file is left behind
+	backup, err := store.LoadBackup()
+	assert.Nil(t, err)
+	assert.Contains(t, string(backup), `"version": 1`)
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		assert.False(t, strings.Contains(entry.Name(), ".tmp"), entry.Name())
+	}
+
+	// a cache written for another environment or collection is ignored
+	assert.Equal(t, `{}`, string(LoadConfigurations(store, "prod", "c1")))
+	assert.Equal(t, `{}`, string(LoadConfigurations(store, "dev", "c2")))
+
+	// a tampered cache falls back to the backup
+	assert.Nil(t, os.WriteFile(store.Path(), []byte(strings.Replace(string(data), `"version": 2`, `"version": 3`, 1)), 0644))
+	assert.Equal(t, `{"version":1}`, string(LoadConfigurations(store, "dev", "c1")))
+
+	// so does a truncated cache, or a missing one
+	assert.Nil(t, os.WriteFile(store.Path(), data[:len(data)/2], 0644))
+	assert.Equal(t, `{"version":1}`, string(LoadConfigurations(store, "dev", "c1")))
+	assert.Nil(t, os.Remove(store.Path()))
+	assert.Equal(t, `{"version":1}`, string(LoadConfigurations(store, "dev", "c1")))
+
+	// a corrupted cache does not replace the backup when it is saved over
+	assert.Nil(t, os.WriteFile(store.Path(), data[:len(data)/2], 0644))
+	StoreConfigurations(store, `{"version":4}`, "dev", "c1")
+	backup, err = store.LoadBackup()
+	assert.Nil(t, err)
+	assert.Contains(t, string(backup), `"version": 1`)
+	assert.Equal(t, `{"version":4}`, string(LoadConfigurations(store, "dev", "c1")))
+
+	// stores without a backup return no configurations
+	memoryStore = NewMemoryCacheStore()
+	memoryStore.Save(data[:len(data)/2])
+	assert.Equal(t, `{}`, string(LoadConfigurations(memoryStore, "dev", "c1")))
+
+	// caches written by older versions of the SDK hold the bare configurations
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, constants.ConfigurationFile), []byte(`{"features":[]}`), 0644))
+	assert.Equal(t, `{"features":[]}`, string(LoadConfigurations(store, "dev", "c1")))
+}